@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"flag"
 	"log/slog"
 	"net/http"
@@ -11,6 +12,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	// Embed the time zone database so that opening hours use the full
+	// Europe/Stockholm time zone, even on systems without a database
+	_ "time/tzdata"
+
 	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
 )
 
@@ -31,7 +36,10 @@ func main() {
 	if *apiKey == "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		var err error
-		authenticatedClient, err = systembolaget.DefaultClient.GetAuthenticatedClient(ctx)
+		authenticatedClient, err = (&systembolaget.Client{
+			Client:      http.DefaultClient,
+			RetryPolicy: systembolaget.DefaultRetryPolicy,
+		}).GetAuthenticatedClient(ctx)
 		cancel()
 		if err != nil {
 			slog.Error("Failed to get an authenticated client", slog.Any("error", err))
@@ -39,8 +47,9 @@ func main() {
 		}
 	} else {
 		authenticatedClient = &systembolaget.AuthenticatedClient{
-			APIKey: *apiKey,
-			Client: http.DefaultClient,
+			APIKey:      *apiKey,
+			Client:      http.DefaultClient,
+			RetryPolicy: systembolaget.DefaultRetryPolicy,
 		}
 	}
 
@@ -60,32 +69,69 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	mux.HandleFunc("/api/v1/stores/{storeId}/products/{productId}", func(w http.ResponseWriter, r *http.Request) {
-		storeID := r.PathValue("storeId")
-		productID := r.PathValue("productId")
-
-		products, err := authenticatedClient.Search(r.Context(), nil, systembolaget.FilterByQuery(productID))
-		if err != nil {
+	mux.HandleFunc("/api/v1/stores/{storeId}", func(w http.ResponseWriter, r *http.Request) {
+		store, err := authenticatedClient.GetStore(r.Context(), r.PathValue("storeId"))
+		if errors.Is(err, systembolaget.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		} else if err != nil {
 			failures.Add(1)
-			slog.Error("Failed to search for product", slog.Any("error", err))
+			slog.Error("Failed to get store", slog.Any("error", err))
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
 
-		if len(products.Products) == 0 {
+		header := w.Header()
+		header.Set("Content-Type", "application/json")
+		header.Set("Cache-Control", "max-age=750") // Ask client to cache for 15min
+
+		encoder := json.NewEncoder(w)
+		_ = encoder.Encode(&store)
+	})
+
+	mux.HandleFunc("/api/v1/stores/{storeId}/calendar.ics", func(w http.ResponseWriter, r *http.Request) {
+		store, err := authenticatedClient.GetStore(r.Context(), r.PathValue("storeId"))
+		if errors.Is(err, systembolaget.ErrNotFound) {
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
+		} else if err != nil {
+			failures.Add(1)
+			slog.Error("Failed to get store", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
 		}
 
-		if len(products.Products) != 1 {
+		header := w.Header()
+		header.Set("Content-Type", "text/calendar; charset=utf-8")
+		header.Set("Content-Disposition", `inline; filename="calendar.ics"`)
+		header.Set("Cache-Control", "max-age=3600") // Ask client to cache for 1h
+
+		_ = systembolaget.WriteICalendar(w, store)
+	})
+
+	mux.HandleFunc("/api/v1/stores/{storeId}/products/{productId}", func(w http.ResponseWriter, r *http.Request) {
+		storeID := r.PathValue("storeId")
+		productID := r.PathValue("productId")
+
+		product, err := authenticatedClient.GetProduct(r.Context(), productID)
+		if errors.Is(err, systembolaget.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		} else if errors.Is(err, systembolaget.ErrAmbiguous) {
 			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
 			return
+		} else if err != nil {
+			failures.Add(1)
+			slog.Error("Failed to get product", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
 		}
 
-		product := products.Products[0]
-
 		stockStatus, err := authenticatedClient.GetStockStatus(r.Context(), storeID, productID)
-		if err != nil {
+		if errors.Is(err, systembolaget.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		} else if err != nil {
 			failures.Add(1)
 			slog.Error("Failed to get product stock status", slog.Any("error", err))
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -147,7 +193,7 @@ func main() {
 			response.Thumbnail = base64.StdEncoding.EncodeToString(v)
 		}
 
-		if images, ok := product.Images(); ok {
+		if images, ok := product.ImagesWithBaseURL(authenticatedClient.WebBaseURL); ok {
 			if len(images) > 0 {
 				response.ImageURL = images[0].URL
 			}
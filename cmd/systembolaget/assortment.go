@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
@@ -14,9 +15,16 @@ import (
 func ActionAssortment(ctx context.Context, cmd *cli.Command) error {
 	log := getLogger(cmd)
 
-	client, err := getClient(ctx, cmd, log)
-	if err != nil {
-		return err
+	// Printing the URL of the search requires no requests, so don't fetch an
+	// API key
+	printURL := cmd.Bool("print-url")
+	var client *systembolaget.AuthenticatedClient
+	if !printURL {
+		var err error
+		client, err = getClient(ctx, cmd, log)
+		if err != nil {
+			return err
+		}
 	}
 
 	delayBetweenPages := cmd.Duration("page-delay")
@@ -121,35 +129,109 @@ func ActionAssortment(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
-	log.Debug("Retrieving cursor")
-	cursor := client.SearchWithCursor(options, filters...)
+	if rawURL := cmd.String("from-url"); rawURL != "" {
+		var query *systembolaget.Query
+		var err error
+		if printURL {
+			// Guessed categories yield the same URL segments, so there's no
+			// need to resolve them
+			query, err = systembolaget.ParseWebURL(rawURL)
+		} else {
+			query, err = client.ResolveWebURL(ctx, rawURL)
+		}
+		if err != nil {
+			return err
+		}
+		filters = applyQuery(options, filters, query)
+	}
+
+	if q := cmd.String("q"); q != "" {
+		query, err := systembolaget.ParseQuery(q)
+		if err != nil {
+			return err
+		}
+		filters = applyQuery(options, filters, query)
+	}
+
+	if printURL {
+		query := &systembolaget.Query{Options: *options, Filters: filters}
+		_, err := fmt.Println(query.WebURL(""))
+		return err
+	}
+
+	options.PageDelay = delayBetweenPages
 
 	encoder := json.NewEncoder(os.Stdout)
 
+	// Without a limit, work around the API's page limit to fetch all results
+	if limit == 0 {
+		log.Debug("Fetching all results")
+		fetchedResults := 0
+		for product, err := range client.AllProducts(ctx, options, filters...) {
+			if err != nil {
+				log.Error("Failed to fetch next item", slog.Any("error", err), slog.Int("results", fetchedResults))
+				return err
+			}
+
+			if err := encoder.Encode(withURL(product, client.WebBaseURL)); err != nil {
+				log.Error("Failed to write result", slog.Any("error", err))
+				return err
+			}
+			fetchedResults++
+		}
+
+		log.Debug("All results have been processed", slog.Int("results", fetchedResults))
+		return nil
+	}
+
 	log.Debug("Fetching results")
 	fetchedResults := 0
-	for cursor.Next(ctx, delayBetweenPages) {
-		if err := encoder.Encode(cursor.At()); err != nil {
-			log.Error("Failed to write result", slog.Any("error", err))
+	totalResults := -1
+pages:
+	for page, err := range client.Pages(ctx, options, filters...) {
+		if err != nil {
+			log.Error("Failed to fetch next item", slog.Any("error", err), slog.Int("results", fetchedResults), slog.Int("resultsLimit", limit), slog.Int("totalResults", totalResults))
 			return err
 		}
 
-		fetchedResults++
-		if limit > 0 && fetchedResults == limit {
-			break
+		totalResults = page.Metadata.FullAssortmentDocumentCount
+		for _, product := range page.Products {
+			if err := encoder.Encode(withURL(product, client.WebBaseURL)); err != nil {
+				log.Error("Failed to write result", slog.Any("error", err))
+				return err
+			}
+
+			fetchedResults++
+			if limit > 0 && fetchedResults == limit {
+				break pages
+			}
 		}
 	}
 
-	totalResults := -1
-	if currentPage := cursor.CurrentPage(); currentPage != nil {
-		totalResults = currentPage.Metadata.FullAssortmentDocumentCount
-	}
+	log.Debug("All results have been processed", slog.Int("results", fetchedResults), slog.Int("resultsLimit", limit), slog.Int("totalResults", totalResults))
+	return nil
+}
 
-	if err := cursor.Error(); err != nil {
-		log.Error("Failed to fetch next item", slog.Any("error", err), slog.Int("results", fetchedResults), slog.Int("resultsLimit", limit), slog.Int("totalResults", totalResults))
-		return err
+// applyQuery adds the filters of a query and overrides options it specifies.
+func applyQuery(options *systembolaget.SearchOptions, filters []systembolaget.SearchFilter, query *systembolaget.Query) []systembolaget.SearchFilter {
+	if query.Options.SortBy != "" {
+		options.SortBy = query.Options.SortBy
+		options.SortDirection = query.Options.SortDirection
+	}
+	if query.Options.PageSize > 0 {
+		options.PageSize = query.Options.PageSize
+	}
+	if query.Options.Page > 0 {
+		options.Page = query.Options.Page
 	}
 
-	log.Debug("All results have been processed", slog.Int("results", fetchedResults), slog.Int("resultsLimit", limit), slog.Int("totalResults", totalResults))
-	return nil
+	return append(filters, query.Filters...)
+}
+
+// withURL adds the product's website URL to the product as "url".
+func withURL(product systembolaget.Product, webBaseURL string) systembolaget.Product {
+	if u, ok := product.WebURL(webBaseURL); ok {
+		product["url"] = u
+	}
+	return product
 }
@@ -21,15 +21,34 @@ func getLogger(cmd *cli.Command) *slog.Logger {
 }
 
 func getClient(ctx context.Context, cmd *cli.Command, log *slog.Logger) (*systembolaget.AuthenticatedClient, error) {
+	rateLimiter := systembolaget.NewRateLimiter(cmd.Float("rate-limit"))
+
 	if apiKey := cmd.String("api-key"); apiKey != "" {
 		return &systembolaget.AuthenticatedClient{
-			APIKey: apiKey,
-			Client: http.DefaultClient,
+			APIKey:      apiKey,
+			Client:      http.DefaultClient,
+			RetryPolicy: systembolaget.DefaultRetryPolicy,
+			RateLimiter: rateLimiter,
 		}, nil
 	}
 
+	parent := &systembolaget.Client{
+		Client:      http.DefaultClient,
+		RetryPolicy: systembolaget.DefaultRetryPolicy,
+		RateLimiter: rateLimiter,
+	}
+
+	if !cmd.Bool("no-key-cache") {
+		path, err := systembolaget.DefaultKeyStorePath()
+		if err != nil {
+			log.Warn("Failed to resolve API key cache, not using cache", slog.Any("error", err))
+		} else {
+			parent.KeyStore = systembolaget.NewFileKeyStore(path)
+		}
+	}
+
 	log.Debug("Fetching API key")
-	client, err := systembolaget.DefaultClient.GetAuthenticatedClient(ctx)
+	client, err := parent.GetAuthenticatedClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get API key, please specify one: %w", err)
 	}
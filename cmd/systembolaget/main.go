@@ -8,6 +8,10 @@ import (
 	"path/filepath"
 	"syscall"
 
+	// Embed the time zone database so that opening hours use the full
+	// Europe/Stockholm time zone, even on systems without a database
+	_ "time/tzdata"
+
 	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
 	"github.com/urfave/cli/v3"
 )
@@ -43,6 +47,15 @@ func main() {
 				Name:  "verbose",
 				Usage: "Enable verbose logs",
 			},
+			&cli.BoolFlag{
+				Name:  "no-key-cache",
+				Usage: "Disable caching of the automatically fetched API key",
+			},
+			&cli.FloatFlag{
+				Name:        "rate-limit",
+				Usage:       "Maximum number of requests per second",
+				DefaultText: "unlimited",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -92,6 +105,18 @@ func main() {
 							},
 						},
 					},
+					&cli.StringFlag{
+						Name:  "q",
+						Usage: "Search using the query language, such as 'category:Öl/\"Ljus lager\" origin:Sverige price:10..40 sort:-ProductLaunchDate'. Combined with other filters",
+					},
+					&cli.StringFlag{
+						Name:  "from-url",
+						Usage: "Search using a search or listing URL from systembolaget.se. Combined with other filters",
+					},
+					&cli.BoolFlag{
+						Name:  "print-url",
+						Usage: "Print the systembolaget.se URL of the search instead of fetching products",
+					},
 					// FilterByStore
 					&cli.StringFlag{
 						Name:  "store",
@@ -214,21 +239,171 @@ func main() {
 						Aliases: []string{"q"},
 						Usage:   "Optional search query",
 					},
+					&cli.StringFlag{
+						Name:  "fuzzy",
+						Usage: "Search stores by name, alias, address or city, ignoring case and accents and allowing for typos. Adds the relevance to each store",
+					},
+					&cli.StringFlag{
+						Name:  "county",
+						Usage: "Only include stores in a county, such as 'Stockholms län'",
+					},
+					&cli.StringFlag{
+						Name:  "city",
+						Usage: "Only include stores in a city, such as 'Göteborg'",
+					},
+					&cli.BoolFlag{
+						Name:  "tasting-store",
+						Usage: "Only include tasting stores. Use --tasting-store=false to exclude them",
+					},
+					&cli.BoolFlag{
+						Name:  "agent",
+						Usage: "Only include agents (ombud). Use --agent=false to exclude them",
+					},
+					&cli.BoolFlag{
+						Name:  "svanen-certified",
+						Usage: "Only include Svanen certified stores. Use --svanen-certified=false to exclude them",
+					},
+					&cli.BoolFlag{
+						Name:  "exclude-blocked",
+						Usage: "Exclude blocked stores",
+					},
+					&cli.StringFlag{
+						Name:  "near",
+						Usage: "Sort stores by their distance to a position, such as '57.70,11.97'. Adds the distance in meters to each store",
+					},
+					&cli.StringFlag{
+						Name:  "radius",
+						Usage: "Only include stores within a distance of the --near position, such as '5km' or '500m'",
+					},
+					&cli.BoolFlag{
+						Name:  "open-now",
+						Usage: "Only include stores that are currently open",
+					},
+					&cli.StringFlag{
+						Name:  "open-at",
+						Usage: "Only include stores open at a time in Swedish time, such as '2024-05-02 18:30' or '18:30' for today",
+					},
+					&cli.IntFlag{
+						Name:        "limit",
+						Usage:       "Maximum number of stores to return",
+						DefaultText: "return all",
+					},
+					&EnumFlag{
+						Name:  "format",
+						Usage: "Output format. The ics format outputs the stores' opening hours as an iCalendar calendar. The geojson and kml formats output stores with a position as a map layer",
+						Value: "json",
+						Config: EnumConfig{
+							Choices: []string{
+								"json",
+								"ics",
+								"geojson",
+								"kml",
+							},
+						},
+					},
+					&cli.StringFlag{
+						Name:  "product-id",
+						Usage: "Include the stock of a product in each store. Requires --format geojson or kml",
+					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of stores to get stock for concurrently",
+						Value: systembolaget.DefaultConcurrency,
+					},
+				},
+			},
+			{
+				Name:   "product",
+				Usage:  "Get products by their id or article number",
+				Action: ActionProduct,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "api-key",
+						Aliases: []string{"k"},
+						Usage:   "API key to use. Defaults to automatically fetching one",
+					},
+					&cli.StringSliceFlag{
+						Name:  "id",
+						Usage: "Product id, such as '5078490'. May be specified multiple times",
+					},
+					&cli.StringSliceFlag{
+						Name:  "number",
+						Usage: "Article number, such as '150701'. Short article numbers are matched as well. May be specified multiple times",
+					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of products to look up concurrently",
+						Value: systembolaget.DefaultConcurrency,
+					},
+				},
+			},
+			{
+				Name:   "stock-matrix",
+				Usage:  "Get current stock of many products in many stores",
+				Action: ActionStockMatrix,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "api-key",
+						Aliases: []string{"k"},
+						Usage:   "API key to use. Defaults to automatically fetching one",
+					},
+					&cli.StringSliceFlag{
+						Name:  "product-id",
+						Usage: "Product to get stock for. May be specified multiple times",
+					},
+					&cli.StringFlag{
+						Name:  "product-file",
+						Usage: "File with one product id per line, or '-' for stdin",
+					},
+					&cli.StringSliceFlag{
+						Name:  "store-id",
+						Usage: "Store to get stock for. May be specified multiple times",
+					},
+					&cli.StringFlag{
+						Name:  "store-file",
+						Usage: "File with one store id per line, or '-' for stdin",
+					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of stock statuses to get concurrently",
+						Value: systembolaget.DefaultConcurrency,
+					},
+					&EnumFlag{
+						Name:  "format",
+						Usage: "Output format",
+						Value: "json",
+						Config: EnumConfig{
+							Choices: []string{
+								"json",
+								"csv",
+								"table",
+							},
+						},
+					},
 				},
 			},
 			{
 				Name:   "stock",
-				Usage:  "Get current stock",
+				Usage:  "Get current stock in one or more stores",
 				Action: ActionStock,
 				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     "store-id",
-						Required: true,
+					&cli.StringSliceFlag{
+						Name:  "store-id",
+						Usage: "Store to get stock for. May be specified multiple times",
+					},
+					&cli.BoolFlag{
+						Name:  "all-stores",
+						Usage: "Get stock for all stores",
 					},
 					&cli.StringFlag{
 						Name:     "product-id",
 						Required: true,
 					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of stores to get stock for concurrently",
+						Value: systembolaget.DefaultConcurrency,
+					},
 				},
 			},
 		},
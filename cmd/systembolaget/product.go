@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
+	"github.com/urfave/cli/v3"
+)
+
+func ActionProduct(ctx context.Context, cmd *cli.Command) error {
+	log := getLogger(cmd)
+
+	ids := cmd.StringSlice("id")
+	numbers := cmd.StringSlice("number")
+	if (len(ids) == 0) == (len(numbers) == 0) {
+		return fmt.Errorf("either --id or --number must be specified")
+	}
+
+	client, err := getClient(ctx, cmd, log)
+	if err != nil {
+		return err
+	}
+	client.Concurrency = cmd.Int("concurrency")
+
+	keys := ids
+	var products map[string]systembolaget.Product
+	if len(ids) > 0 {
+		products, err = client.GetProducts(ctx, ids)
+	} else {
+		keys = numbers
+		products, err = client.GetProductsByNumber(ctx, numbers)
+	}
+
+	var batchErr *systembolaget.BatchError
+	if errors.As(err, &batchErr) {
+		for key, err := range batchErr.Errors {
+			log.Error("Failed to get product", slog.String("key", key), slog.Any("error", err))
+		}
+	} else if err != nil {
+		return err
+	}
+
+	// Output products in the order they were requested
+	encoder := json.NewEncoder(os.Stdout)
+	written := make(map[string]struct{}, len(products))
+	for _, key := range keys {
+		product, ok := products[key]
+		if _, done := written[key]; !ok || done {
+			continue
+		}
+		written[key] = struct{}{}
+
+		if err := encoder.Encode(withURL(product, client.WebBaseURL)); err != nil {
+			return err
+		}
+	}
+
+	if batchErr != nil {
+		return fmt.Errorf("failed to get %d of the products", len(batchErr.Errors))
+	}
+
+	return nil
+}
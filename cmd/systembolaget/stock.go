@@ -3,24 +3,67 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 
+	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
 	"github.com/urfave/cli/v3"
 )
 
 func ActionStock(ctx context.Context, cmd *cli.Command) error {
 	log := getLogger(cmd)
 
+	storeIDs := cmd.StringSlice("store-id")
+	allStores := cmd.Bool("all-stores")
+	if (len(storeIDs) == 0) == !allStores {
+		return fmt.Errorf("either --store-id or --all-stores must be specified")
+	}
+
 	client, err := getClient(ctx, cmd, log)
 	if err != nil {
 		return err
 	}
+	client.Concurrency = cmd.Int("concurrency")
 
-	stores, err := client.GetStockStatus(ctx, cmd.String("store-id"), cmd.String("product-id"))
-	if err != nil {
+	encoder := json.NewEncoder(os.Stdout)
+
+	// Keep the output of a single store as is
+	if len(storeIDs) == 1 {
+		status, err := client.GetStockStatus(ctx, storeIDs[0], cmd.String("product-id"))
+		if err != nil {
+			return err
+		}
+
+		return encoder.Encode(status)
+	}
+
+	var statuses []systembolaget.StockStatus
+	if allStores {
+		statuses, err = client.GetStockInAllStores(ctx, cmd.String("product-id"))
+	} else {
+		statuses, err = client.GetStockAcrossStores(ctx, cmd.String("product-id"), storeIDs)
+	}
+
+	var batchErr *systembolaget.BatchError
+	if errors.As(err, &batchErr) {
+		for storeID, err := range batchErr.Errors {
+			log.Error("Failed to get stock status", slog.String("storeId", storeID), slog.Any("error", err))
+		}
+	} else if err != nil {
 		return err
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
-	return encoder.Encode(stores)
+	for _, status := range statuses {
+		if err := encoder.Encode(&status); err != nil {
+			return err
+		}
+	}
+
+	if batchErr != nil {
+		return fmt.Errorf("failed to get stock status of %d stores", len(batchErr.Errors))
+	}
+
+	return nil
 }
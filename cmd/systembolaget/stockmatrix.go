@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
+	"github.com/urfave/cli/v3"
+)
+
+func ActionStockMatrix(ctx context.Context, cmd *cli.Command) error {
+	log := getLogger(cmd)
+
+	productIDs, err := readIDs(cmd.StringSlice("product-id"), cmd.String("product-file"))
+	if err != nil {
+		return err
+	}
+
+	storeIDs, err := readIDs(cmd.StringSlice("store-id"), cmd.String("store-file"))
+	if err != nil {
+		return err
+	}
+
+	if len(productIDs) == 0 || len(storeIDs) == 0 {
+		return fmt.Errorf("at least one product and one store must be specified")
+	}
+
+	client, err := getClient(ctx, cmd, log)
+	if err != nil {
+		return err
+	}
+	client.Concurrency = cmd.Int("concurrency")
+
+	matrix, err := client.GetStockMatrix(ctx, productIDs, storeIDs)
+	var batchErr *systembolaget.BatchError
+	if errors.As(err, &batchErr) {
+		for key, err := range batchErr.Errors {
+			log.Error("Failed to get stock status", slog.String("key", key), slog.Any("error", err))
+		}
+	} else if err != nil {
+		return err
+	}
+
+	switch cmd.String("format") {
+	case "csv":
+		err = writeStockMatrixCSV(os.Stdout, matrix)
+	case "table":
+		err = writeStockMatrixTable(os.Stdout, matrix)
+	default:
+		err = json.NewEncoder(os.Stdout).Encode(matrix)
+	}
+	if err != nil {
+		return err
+	}
+
+	if batchErr != nil {
+		return fmt.Errorf("failed to get %d of the stock statuses", len(batchErr.Errors))
+	}
+
+	return nil
+}
+
+// readIDs returns ids followed by the ids read from path, if any. The file
+// holds one id per line. Empty lines and lines starting with # are ignored.
+// A path of "-" reads from stdin.
+func readIDs(ids []string, path string) ([]string, error) {
+	if path == "" {
+		return ids, nil
+	}
+
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+
+	return ids, scanner.Err()
+}
+
+// writeStockMatrixCSV writes one row per product and store.
+func writeStockMatrixCSV(w io.Writer, matrix *systembolaget.StockMatrix) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"productId", "storeId", "stock", "shelf", "isInStoreAssortment"})
+
+	for i, productID := range matrix.ProductIDs {
+		for j, storeID := range matrix.StoreIDs {
+			status := matrix.Statuses[i][j]
+			if status == nil {
+				writer.Write([]string{productID, storeID, "", "", ""})
+				continue
+			}
+
+			writer.Write([]string{
+				productID,
+				storeID,
+				strconv.Itoa(status.Stock),
+				status.Shelf,
+				strconv.FormatBool(status.IsInStoreAssortment),
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeStockMatrixTable writes an aligned table with a row per product and a
+// column per store. Each cell holds the stock and shelf. Products not in the
+// store's assortment are marked with "-" and failed lookups with "?".
+func writeStockMatrixTable(w io.Writer, matrix *systembolaget.StockMatrix) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprint(writer, "PRODUCT")
+	for _, storeID := range matrix.StoreIDs {
+		fmt.Fprintf(writer, "\t%s", storeID)
+	}
+	fmt.Fprintln(writer)
+
+	for i, productID := range matrix.ProductIDs {
+		fmt.Fprint(writer, productID)
+		for j := range matrix.StoreIDs {
+			status := matrix.Statuses[i][j]
+			switch {
+			case status == nil:
+				fmt.Fprint(writer, "\t?")
+			case !status.IsInStoreAssortment && status.Stock == 0:
+				fmt.Fprint(writer, "\t-")
+			case status.Shelf != "":
+				fmt.Fprintf(writer, "\t%d (%s)", status.Stock, status.Shelf)
+			default:
+				fmt.Fprintf(writer, "\t%d", status.Stock)
+			}
+		}
+		fmt.Fprintln(writer)
+	}
+
+	return writer.Flush()
+}
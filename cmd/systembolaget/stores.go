@@ -3,14 +3,66 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alexgustafsson/systembolaget-api/v5/systembolaget"
 	"github.com/urfave/cli/v3"
 )
 
 func ActionStores(ctx context.Context, cmd *cli.Command) error {
 	log := getLogger(cmd)
 
+	var near *systembolaget.StorePosition
+	if value := cmd.String("near"); value != "" {
+		position, err := parsePosition(value)
+		if err != nil {
+			return fmt.Errorf("invalid --near: %w", err)
+		}
+		near = &position
+	}
+
+	radius := 0.0
+	if value := cmd.String("radius"); value != "" {
+		if near == nil {
+			return fmt.Errorf("--radius requires --near")
+		}
+
+		var err error
+		radius, err = parseDistance(value)
+		if err != nil {
+			return fmt.Errorf("invalid --radius: %w", err)
+		}
+	}
+
+	var openAt time.Time
+	if value := cmd.String("open-at"); value != "" {
+		if cmd.Bool("open-now") {
+			return fmt.Errorf("--open-now and --open-at are mutually exclusive")
+		}
+
+		var err error
+		openAt, err = parseStoreTime(value, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --open-at: %w", err)
+		}
+	} else if cmd.Bool("open-now") {
+		openAt = time.Now()
+	}
+
+	limit := cmd.Int("limit")
+
+	format := cmd.String("format")
+	productID := cmd.String("product-id")
+	if productID != "" && format != "geojson" && format != "kml" {
+		return fmt.Errorf("--product-id requires --format geojson or kml")
+	}
+
 	client, err := getClient(ctx, cmd, log)
 	if err != nil {
 		return err
@@ -21,12 +73,172 @@ func ActionStores(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
-	for _, store := range stores {
-		err := encoder.Encode(store)
-		if err != nil {
+	query := &systembolaget.StoreQuery{
+		Text:           cmd.String("fuzzy"),
+		County:         cmd.String("county"),
+		City:           cmd.String("city"),
+		ExcludeBlocked: cmd.Bool("exclude-blocked"),
+	}
+
+	if cmd.IsSet("tasting-store") {
+		value := cmd.Bool("tasting-store")
+		query.IsTastingStore = &value
+	}
+
+	if cmd.IsSet("agent") {
+		value := cmd.Bool("agent")
+		query.IsAgent = &value
+	}
+
+	if cmd.IsSet("svanen-certified") {
+		value := cmd.Bool("svanen-certified")
+		query.IsSvanenCertified = &value
+	}
+
+	if !openAt.IsZero() {
+		query.Predicates = append(query.Predicates, func(store systembolaget.Store) bool {
+			return store.IsOpenAt(openAt)
+		})
+	}
+
+	matches := query.Filter(stores)
+	stores = make([]systembolaget.Store, 0, len(matches))
+	for _, match := range matches {
+		stores = append(stores, match.Store)
+	}
+
+	// Keep the stores to output along with their JSON representation, which
+	// includes the distance or score when relevant
+	var results []systembolaget.Store
+	var values []any
+
+	if near != nil {
+		var distances []systembolaget.StoreDistance
+		if radius > 0 {
+			distances = systembolaget.StoresWithinRadius(stores, near.Latitude, near.Longitude, radius)
+		} else {
+			distances = systembolaget.NearestStores(stores, near.Latitude, near.Longitude, 0)
+		}
+
+		for _, distance := range distances {
+			results = append(results, distance.Store)
+			values = append(values, distance)
+		}
+	} else {
+		for _, match := range matches {
+			results = append(results, match.Store)
+			// Only include the score when ranking by relevance
+			if query.Text != "" {
+				values = append(values, match)
+			} else {
+				values = append(values, match.Store)
+			}
+		}
+	}
+
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+		values = values[:limit]
+	}
+
+	var stock map[string]systembolaget.StockStatus
+	if productID != "" {
+		storeIDs := make([]string, 0, len(results))
+		for _, store := range results {
+			storeIDs = append(storeIDs, store.SiteID)
+		}
+
+		client.Concurrency = cmd.Int("concurrency")
+		statuses, err := client.GetStockAcrossStores(ctx, productID, storeIDs)
+		var batchErr *systembolaget.BatchError
+		if errors.As(err, &batchErr) {
+			for storeID, err := range batchErr.Errors {
+				log.Warn("Failed to get stock status, excluding it", slog.String("storeId", storeID), slog.Any("error", err))
+			}
+		} else if err != nil {
 			return err
 		}
+
+		stock = make(map[string]systembolaget.StockStatus, len(statuses))
+		for _, status := range statuses {
+			stock[status.StoreID] = status
+		}
 	}
-	return nil
+
+	switch format {
+	case "ics":
+		return systembolaget.WriteICalendar(os.Stdout, results...)
+	case "geojson":
+		return systembolaget.WriteGeoJSON(os.Stdout, results, stock)
+	case "kml":
+		return systembolaget.WriteKML(os.Stdout, results, stock)
+	default:
+		encoder := json.NewEncoder(os.Stdout)
+		for _, value := range values {
+			if err := encoder.Encode(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// parsePosition parses a position formatted as "latitude,longitude", such as
+// "57.70,11.97".
+func parsePosition(value string) (systembolaget.StorePosition, error) {
+	latitudeValue, longitudeValue, ok := strings.Cut(value, ",")
+	if !ok {
+		return systembolaget.StorePosition{}, fmt.Errorf("expected latitude,longitude")
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(latitudeValue), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return systembolaget.StorePosition{}, fmt.Errorf("invalid latitude: %s", latitudeValue)
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(longitudeValue), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return systembolaget.StorePosition{}, fmt.Errorf("invalid longitude: %s", longitudeValue)
+	}
+
+	return systembolaget.StorePosition{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// parseDistance parses a distance in meters, such as "5km", "500m" or "500".
+func parseDistance(value string) (float64, error) {
+	multiplier := 1.0
+	number := strings.TrimSpace(value)
+	if trimmed, ok := strings.CutSuffix(number, "km"); ok {
+		number, multiplier = trimmed, 1000
+	} else if trimmed, ok := strings.CutSuffix(number, "m"); ok {
+		number = trimmed
+	}
+
+	distance, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || distance <= 0 {
+		return 0, fmt.Errorf("expected a positive distance such as 5km or 500m")
+	}
+
+	return distance * multiplier, nil
+}
+
+// parseStoreTime parses a time in the Europe/Stockholm time zone, such as
+// "2024-05-02 18:30" or "18:30" for today, or an RFC 3339 time.
+func parseStoreTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, systembolaget.Stockholm); err == nil {
+			return t, nil
+		}
+	}
+
+	if clock, err := time.Parse("15:04", value); err == nil {
+		today := now.In(systembolaget.Stockholm)
+		return time.Date(today.Year(), today.Month(), today.Day(), clock.Hour(), clock.Minute(), 0, 0, systembolaget.Stockholm), nil
+	}
+
+	return time.Time{}, fmt.Errorf("expected a time such as '2024-05-02 18:30' or '18:30'")
 }
@@ -0,0 +1,133 @@
+package systembolaget
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the default number of concurrent requests made by
+// batch methods such as [AuthenticatedClient.GetProducts].
+const DefaultConcurrency = 4
+
+// BatchError is returned by batch methods when one or more items failed.
+// Items that succeeded are still returned.
+type BatchError struct {
+	// Errors holds the error of each failed item, keyed by item, such as the
+	// product id.
+	Errors map[string]error
+}
+
+// Error implements error.
+func (e *BatchError) Error() string {
+	keys := slices.Sorted(maps.Keys(e.Errors))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d of the items failed", len(keys))
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "\n%s: %s", key, e.Errors[key])
+	}
+	return builder.String()
+}
+
+// Unwrap returns the errors of each failed item.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, key := range slices.Sorted(maps.Keys(e.Errors)) {
+		errs = append(errs, e.Errors[key])
+	}
+	return errs
+}
+
+// GetProducts looks up the products with the given ids using
+// [AuthenticatedClient.GetProduct]. Duplicate ids are looked up once.
+//
+// Lookups are made concurrently, bounded by the client's Concurrency and
+// limited by its RateLimiter. The returned map holds the products that were
+// found, keyed by id. If any lookup fails, a [*BatchError] holding the error of
+// each failed id is returned along with the products that were found.
+func (c *AuthenticatedClient) GetProducts(ctx context.Context, ids []string) (map[string]Product, error) {
+	products, errs := batch(ctx, c.Concurrency, ids, c.GetProduct)
+	return products, newBatchError(errs)
+}
+
+// GetProductsByNumber looks up the products with the given article numbers
+// using [AuthenticatedClient.GetProductByNumber]. The results are keyed by
+// article number, see [AuthenticatedClient.GetProducts].
+func (c *AuthenticatedClient) GetProductsByNumber(ctx context.Context, numbers []string) (map[string]Product, error) {
+	products, errs := batch(ctx, c.Concurrency, numbers, c.GetProductByNumber)
+	return products, newBatchError(errs)
+}
+
+// newBatchError returns a [*BatchError] holding errs, or nil if there are no
+// errors.
+func newBatchError(errs map[string]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: errs}
+}
+
+// batch calls fn for each unique key using at most concurrency workers. Keys
+// not yet processed when ctx is done fail with the context's error. Returns
+// the results and errors of each key.
+func batch[K comparable, T any](ctx context.Context, concurrency int, keys []K, fn func(context.Context, K) (T, error)) (map[K]T, map[K]error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	unique := uniqueValues(keys)
+
+	var mutex sync.Mutex
+	results := make(map[K]T, len(unique))
+	errs := make(map[K]error)
+
+	jobs := make(chan K)
+	go func() {
+		defer close(jobs)
+		for _, key := range unique {
+			jobs <- key
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(unique)) {
+		wg.Go(func() {
+			for key := range jobs {
+				var result T
+				err := ctx.Err()
+				if err == nil {
+					result, err = fn(ctx, key)
+				}
+
+				mutex.Lock()
+				if err == nil {
+					results[key] = result
+				} else {
+					errs[key] = err
+				}
+				mutex.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// uniqueValues returns values without duplicates, keeping the first occurrence
+// of each value.
+func uniqueValues[K comparable](values []K) []K {
+	unique := make([]K, 0, len(values))
+	seen := make(map[K]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
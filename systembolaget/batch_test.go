@@ -0,0 +1,71 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticatedClientGetProducts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		query := r.URL.Query().Get("textQuery")
+		if query == "500" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var result SearchResult
+		result.Metadata.NextPage = -1
+		if query != "404" {
+			result.Products = []Product{{"productId": query}}
+		}
+		json.NewEncoder(w).Encode(&result)
+	}))
+	defer server.Close()
+
+	client := &AuthenticatedClient{
+		Client:      server.Client(),
+		APIBaseURL:  server.URL,
+		RateLimiter: NewRateLimiter(1000),
+	}
+
+	products, err := client.GetProducts(context.TODO(), []string{"1", "2", "1", "404", "500", "2"})
+	require.Error(t, err)
+
+	assert.Len(t, products, 2)
+	assert.Equal(t, "1", products["1"]["productId"])
+	assert.Equal(t, "2", products["2"]["productId"])
+
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 2)
+	assert.ErrorIs(t, batchErr.Errors["404"], ErrNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var apiErr *APIError
+	assert.ErrorAs(t, batchErr.Errors["500"], &apiErr)
+
+	// Duplicates are only looked up once. The missing product is also looked up
+	// using the fallback search
+	assert.EqualValues(t, 5, requests.Load())
+}
+
+func TestAuthenticatedClientGetProductsCancelled(t *testing.T) {
+	client := &AuthenticatedClient{APIBaseURL: "http://127.0.0.1:0"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	products, err := client.GetProducts(ctx, []string{"1", "2"})
+	assert.Empty(t, products)
+	assert.ErrorIs(t, err, context.Canceled)
+}
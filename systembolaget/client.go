@@ -1,7 +1,22 @@
 package systembolaget
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultAPIBaseURL is the base URL of Systembolaget's API.
+	DefaultAPIBaseURL = "https://api-extern.systembolaget.se"
+	// DefaultWebBaseURL is the base URL of Systembolaget's website.
+	DefaultWebBaseURL = "https://www.systembolaget.se"
 )
 
 var DefaultClient = &Client{
@@ -12,14 +27,135 @@ var DefaultClient = &Client{
 type Client struct {
 	Client    *http.Client
 	UserAgent string
+	// APIBaseURL is the base URL of the API, such as
+	// "https://api-extern.systembolaget.se". Defaults to [DefaultAPIBaseURL].
+	APIBaseURL string
+	// WebBaseURL is the base URL of the website, such as
+	// "https://www.systembolaget.se". Defaults to [DefaultWebBaseURL].
+	WebBaseURL string
+	// RetryPolicy specifies how failed requests are retried. Defaults to no
+	// retries.
+	RetryPolicy *RetryPolicy
+	// RateLimiter optionally limits the rate of requests. It is shared with
+	// clients returned by [Client.GetAuthenticatedClient].
+	RateLimiter *RateLimiter
+	// KeyStore optionally persists API keys retrieved by
+	// [Client.GetAuthenticatedClient] so that they may be reused.
+	KeyStore KeyStore
+	// KeyTTL is the duration for which a stored API key is reused. Defaults to
+	// [DefaultKeyTTL].
+	KeyTTL time.Duration
+	// KeyExtractors are the strategies used to identify the API key, tried in
+	// order. Defaults to [DefaultKeyExtractors].
+	KeyExtractors []KeyExtractor
 }
 
 // AuthenticatedClient is a Systembolaget API client for authenticated methods.
 //
 // An [AuthenticatedClient] is typically retrieved by calling
 // [Client.GetAuthenticatedClient].
+//
+// An [AuthenticatedClient] must not be copied after first use.
 type AuthenticatedClient struct {
+	// APIKey is the initial API key. Use [AuthenticatedClient.CurrentAPIKey] to
+	// get the key currently in use, which may differ if the key has been
+	// refreshed.
 	APIKey    string
 	Client    *http.Client
 	UserAgent string
+	// APIBaseURL is the base URL of the API, such as
+	// "https://api-extern.systembolaget.se". Defaults to [DefaultAPIBaseURL].
+	APIBaseURL string
+	// WebBaseURL is the base URL of the website, such as
+	// "https://www.systembolaget.se". Defaults to [DefaultWebBaseURL].
+	WebBaseURL string
+	// RetryPolicy specifies how failed requests are retried. Defaults to no
+	// retries.
+	RetryPolicy *RetryPolicy
+	// RateLimiter optionally limits the rate of requests, including those made
+	// concurrently by batch methods such as [AuthenticatedClient.GetProducts].
+	RateLimiter *RateLimiter
+	// Concurrency is the maximum number of concurrent requests made by batch
+	// methods such as [AuthenticatedClient.GetProducts]. Defaults to
+	// [DefaultConcurrency].
+	Concurrency int
+	// Parent is the client used to retrieve the API key. If set, the API key is
+	// refreshed using [Client.GetAPIKey] when the server rejects it, after which
+	// the request is replayed once.
+	Parent *Client
+
+	// refreshMutex is held while refreshing the API key.
+	refreshMutex sync.Mutex
+	// refreshedAPIKey holds the latest refreshed API key, if any.
+	refreshedAPIKey atomic.Pointer[string]
+
+	// storeDirectoryOnce guards the creation of storeDirectory.
+	storeDirectoryOnce sync.Once
+	// storeDirectory is used by [AuthenticatedClient.GetStore].
+	storeDirectory *StoreDirectory
+}
+
+// resolveURL returns the URL of path relative to baseURL, or fallback if
+// baseURL is empty.
+func resolveURL(baseURL string, fallback string, path string, query url.Values) (*url.URL, error) {
+	if baseURL == "" {
+		baseURL = fallback
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+
+	return u, nil
+}
+
+// webOrigin returns the origin of the website, as used in the Origin header.
+func webOrigin(webBaseURL string) string {
+	if webBaseURL == "" {
+		return DefaultWebBaseURL
+	}
+
+	u, err := url.Parse(webBaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return DefaultWebBaseURL
+	}
+
+	return u.Scheme + "://" + u.Host
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return do(c.Client, c.RetryPolicy, c.RateLimiter, req)
+}
+
+// do performs an authenticated request. If the API key is rejected and the
+// client has a parent, the key is refreshed and the request is replayed once.
+func (c *AuthenticatedClient) do(req *http.Request) (*http.Response, error) {
+	apiKey := c.CurrentAPIKey()
+	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
+
+	res, err := do(c.Client, c.RetryPolicy, c.RateLimiter, req)
+	if c.Parent == nil || !(errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden)) {
+		return res, err
+	}
+
+	slog.Debug("API key was rejected, refreshing", slog.Any("error", err))
+	if refreshErr := c.refreshAPIKey(req.Context(), apiKey); refreshErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to refresh API key: %w", refreshErr))
+	}
+
+	req.Header.Set("Ocp-Apim-Subscription-Key", c.CurrentAPIKey())
+	return do(c.Client, c.RetryPolicy, c.RateLimiter, req)
+}
+
+func (c *Client) webURL(path string) (*url.URL, error) {
+	return resolveURL(c.WebBaseURL, DefaultWebBaseURL, path, nil)
+}
+
+func (c *AuthenticatedClient) apiURL(path string, query url.Values) (*url.URL, error) {
+	return resolveURL(c.APIBaseURL, DefaultAPIBaseURL, path, query)
 }
@@ -0,0 +1,101 @@
+package systembolaget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientBaseURLs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<script src="/_next/static/chunks/app.js" defer=""></script>`))
+	})
+	mux.HandleFunc("/_next/static/chunks/app.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`env:{NEXT_PUBLIC_API_KEY_APIM:"test-key"}`))
+	})
+	mux.HandleFunc("/sb-api-ecommerce/v1/productsearch/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("Ocp-Apim-Subscription-Key"))
+		w.Write([]byte(`{"products":[{"productId":"1","images":[{"imageUrl":"https://example.com/1"}]}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &Client{
+		Client:     server.Client(),
+		APIBaseURL: server.URL,
+		WebBaseURL: server.URL,
+	}
+
+	authenticatedClient, err := client.GetAuthenticatedClient(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "test-key", authenticatedClient.APIKey)
+
+	result, err := authenticatedClient.Search(context.TODO(), nil)
+	require.NoError(t, err)
+	require.Len(t, result.Products, 1)
+
+	images, ok := result.Products[0].ImagesWithBaseURL(authenticatedClient.WebBaseURL)
+	require.True(t, ok)
+	require.Len(t, images, 1)
+
+	u, err := url.Parse(images[0].URL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/_next/image/", u.Scheme+"://"+u.Host+u.Path)
+}
+
+func TestAuthenticatedClientRefreshesAPIKey(t *testing.T) {
+	var scrapes atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		scrapes.Add(1)
+		w.Write([]byte(`<script src="/_next/static/chunks/app.js" defer=""></script>`))
+	})
+	mux.HandleFunc("/_next/static/chunks/app.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`env:{NEXT_PUBLIC_API_KEY_APIM:"new-key"}`))
+	})
+	mux.HandleFunc("/sb-api-ecommerce/v1/stockbalance/store/{storeId}/{productId}/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "new-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"stock":1}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	parent := &Client{
+		Client:     server.Client(),
+		APIBaseURL: server.URL,
+		WebBaseURL: server.URL,
+	}
+
+	client := &AuthenticatedClient{
+		APIKey:     "old-key",
+		Client:     server.Client(),
+		APIBaseURL: server.URL,
+		WebBaseURL: server.URL,
+		Parent:     parent,
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			status, err := client.GetStockStatus(context.TODO(), "0102", "507849")
+			if assert.NoError(t, err) {
+				assert.Equal(t, 1, status.Stock)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, "new-key", client.CurrentAPIKey())
+	assert.Equal(t, int32(1), scrapes.Load())
+}
@@ -3,118 +3,86 @@ package systembolaget
 import (
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
-	"regexp"
-	"strings"
 )
 
-var apiTokenRegex = regexp.MustCompile(`NEXT_PUBLIC_API_KEY_APIM:"([^"]+)"`)
-
-// <script src="/_next/static/chunks/131pjojsj1pi9.js" defer=""></script>
-var chunkPathRegex = regexp.MustCompile(`src="(/_next/static/chunks/[^"]+\.js)"`)
-
 // GetAPIKey returns the API credentials used by the Systembolaget
-// frontend.
+// frontend. See [Client.ExtractAPIKey].
 func (c *Client) GetAPIKey(ctx context.Context) (string, error) {
-	chunkPaths, err := c.getChunkPaths(ctx)
+	apiKey, report, err := c.ExtractAPIKey(ctx)
 	if err != nil {
+		slog.Error("Failed to extract API key", slog.Any("error", err))
 		return "", err
 	}
 
-	for _, chunkPath := range chunkPaths {
-		key, err := c.extractAPIKey(ctx, chunkPath)
-		if err == nil {
-			return key, nil
-		}
-	}
-
-	return "", fmt.Errorf("unable to identify API token in any script chunk")
+	slog.Debug("Extracted API key", slog.String("extractor", report.Extractor))
+	return apiKey, nil
 }
 
 // GetAuthenticatedClient calls [Client.GetAPIKey] and returns an initialized
 // [AuthenticatedClient] using the key and defaults from the client.
+// The returned client refreshes its API key using c when it's rejected.
+//
+// If the client has a [KeyStore], a stored key is used instead as long as it
+// has not expired and is accepted by the server. Retrieved keys are stored.
+// If the stored key can't be validated, for example due to network errors,
+// the error is returned rather than retrieving a new key.
 func (c *Client) GetAuthenticatedClient(ctx context.Context) (*AuthenticatedClient, error) {
-	apiKey, err := c.GetAPIKey(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	return &AuthenticatedClient{
-		APIKey:    apiKey,
-		Client:    c.Client,
-		UserAgent: c.UserAgent,
-	}, nil
-}
-
-func (c *Client) getChunkPaths(ctx context.Context) ([]string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.systembolaget.se", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		slog.Error("Request failed", slog.Any("error", err))
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		slog.Error("Got unexpected status code", slog.Int("statusCode", res.StatusCode), slog.String("status", res.Status))
-		return nil, fmt.Errorf("unexpected status code: %d - %s", res.StatusCode, res.Status)
-	}
-
-	source, err := io.ReadAll(res.Body)
-	if err != nil {
-		slog.Error("Failed to read body")
-		return nil, err
-	}
-
-	matches := chunkPathRegex.FindAllSubmatch(source, -1)
-	if len(matches) == 0 {
-		slog.Error("Unable to find script chunks")
-		return nil, fmt.Errorf("unable to identify script chunks")
+	apiKey, ok := "", false
+	if c.KeyStore != nil {
+		var err error
+		apiKey, ok, err = c.loadAPIKey(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate stored API key: %w", err)
+		}
 	}
 
-	paths := make([]string, 0, len(matches))
-	for _, match := range matches {
-		path := string(match[1])
-		if strings.HasPrefix(path, "/") {
-			path = "https://www.systembolaget.se" + path
+	if !ok {
+		var err error
+		apiKey, err = c.GetAPIKey(ctx)
+		if err != nil {
+			return nil, err
 		}
-		paths = append(paths, path)
+		c.storeAPIKey(ctx, apiKey)
 	}
 
-	return paths, nil
+	return &AuthenticatedClient{
+		APIKey:      apiKey,
+		Client:      c.Client,
+		UserAgent:   c.UserAgent,
+		APIBaseURL:  c.APIBaseURL,
+		WebBaseURL:  c.WebBaseURL,
+		RetryPolicy: c.RetryPolicy,
+		RateLimiter: c.RateLimiter,
+		Parent:      c,
+	}, nil
 }
 
-func (c *Client) extractAPIKey(ctx context.Context, url string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return "", err
+// CurrentAPIKey returns the API key currently in use.
+func (c *AuthenticatedClient) CurrentAPIKey() string {
+	if apiKey := c.refreshedAPIKey.Load(); apiKey != nil {
+		return *apiKey
 	}
+	return c.APIKey
+}
 
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer res.Body.Close()
+// refreshAPIKey retrieves a new API key using the client's parent.
+// Concurrent refreshes are collapsed into a single one - if the key no longer
+// matches the rejected key, it has already been refreshed by another caller.
+func (c *AuthenticatedClient) refreshAPIKey(ctx context.Context, rejectedAPIKey string) error {
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
 
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d - %s", res.StatusCode, res.Status)
+	if c.CurrentAPIKey() != rejectedAPIKey {
+		return nil
 	}
 
-	source, err := io.ReadAll(res.Body)
+	apiKey, err := c.Parent.GetAPIKey(ctx)
 	if err != nil {
-		return "", err
-	}
-
-	match := apiTokenRegex.FindSubmatch(source)
-	if match == nil {
-		return "", fmt.Errorf("api token not found in chunk")
+		return err
 	}
 
-	return string(match[1]), nil
+	c.refreshedAPIKey.Store(&apiKey)
+	c.Parent.storeAPIKey(ctx, apiKey)
+	return nil
 }
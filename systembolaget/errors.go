@@ -0,0 +1,128 @@
+package systembolaget
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when a resource, such as a product or a store,
+	// does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is returned when the API key is missing or invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the API key is not allowed to access a
+	// resource.
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited is returned when the server throttles requests.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// maxErrorBodySize is the maximum number of bytes of a response body to
+// include in an [APIError].
+const maxErrorBodySize = 512
+
+// requestIDHeaders are headers that may identify a request in the server's
+// logs, in order of preference.
+var requestIDHeaders = []string{
+	"Request-Id",
+	"X-Request-Id",
+	"Apim-Request-Id",
+	"X-Ms-Request-Id",
+	"X-Correlation-Id",
+}
+
+// APIError is returned when a server responds with an unexpected status
+// code.
+//
+// Use [errors.Is] with [ErrNotFound], [ErrUnauthorized], [ErrForbidden] or
+// [ErrRateLimited] to check for common failures.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response, such as 404.
+	StatusCode int
+	// Status is the HTTP status of the response, such as "404 Not Found".
+	Status string
+	// Endpoint is the method and URL of the request, excluding any query.
+	Endpoint string
+	// RequestID is the ID of the request, if the server returned one.
+	RequestID string
+	// Body is the beginning of the response body.
+	Body string
+}
+
+// newAPIError creates an [APIError] from a response, consuming at most
+// [maxErrorBodySize] bytes of its body.
+func newAPIError(res *http.Response) *APIError {
+	err := &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+	}
+
+	if res.Request != nil && res.Request.URL != nil {
+		u := *res.Request.URL
+		u.RawQuery = ""
+		u.Fragment = ""
+		err.Endpoint = res.Request.Method + " " + u.String()
+	}
+
+	for _, header := range requestIDHeaders {
+		if v := res.Header.Get(header); v != "" {
+			err.RequestID = v
+			break
+		}
+	}
+
+	if res.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize+1))
+		if len(body) > maxErrorBodySize {
+			err.Body = strings.ToValidUTF8(string(body[:maxErrorBodySize]), "") + "..."
+		} else {
+			err.Body = string(body)
+		}
+	}
+
+	return err
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "unexpected status code: %d - %s", e.StatusCode, e.Status)
+	if e.Endpoint != "" {
+		fmt.Fprintf(&builder, " (%s)", e.Endpoint)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&builder, " (request id %s)", e.RequestID)
+	}
+	return builder.String()
+}
+
+// Is implements errors.Is, matching the sentinel errors of the package.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// Retryable returns whether or not the request may succeed if retried, such
+// as when the server is throttling requests or is temporarily unavailable.
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented
+	}
+}
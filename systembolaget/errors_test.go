@@ -0,0 +1,55 @@
+package systembolaget
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	testCases := []struct {
+		StatusCode int
+		Sentinel   error
+		Retryable  bool
+	}{
+		{StatusCode: http.StatusNotFound, Sentinel: ErrNotFound},
+		{StatusCode: http.StatusUnauthorized, Sentinel: ErrUnauthorized},
+		{StatusCode: http.StatusForbidden, Sentinel: ErrForbidden},
+		{StatusCode: http.StatusTooManyRequests, Sentinel: ErrRateLimited, Retryable: true},
+		{StatusCode: http.StatusBadGateway, Retryable: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(http.StatusText(testCase.StatusCode), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Request-Id", "abc")
+				w.WriteHeader(testCase.StatusCode)
+				w.Write([]byte(strings.Repeat("x", 2*maxErrorBodySize)))
+			}))
+			defer server.Close()
+
+			client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+			_, err := client.GetStockStatus(context.TODO(), "0102", "507849")
+			require.Error(t, err)
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, testCase.StatusCode, apiErr.StatusCode)
+			assert.Equal(t, "abc", apiErr.RequestID)
+			assert.Equal(t, "GET "+server.URL+"/sb-api-ecommerce/v1/stockbalance/store/0102/507849/", apiErr.Endpoint)
+			assert.Len(t, apiErr.Body, maxErrorBodySize+len("..."))
+			assert.Equal(t, testCase.Retryable, apiErr.Retryable())
+
+			if testCase.Sentinel != nil {
+				assert.ErrorIs(t, err, testCase.Sentinel)
+			}
+			assert.NotErrorIs(t, err, errors.New("other"))
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package systembolaget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FacetKind describes what a [Facet] filters by.
+type FacetKind string
+
+const (
+	FacetKindCategory     FacetKind = "category"
+	FacetKindCountry      FacetKind = "country"
+	FacetKindGrapes       FacetKind = "grapes"
+	FacetKindSeal         FacetKind = "seal"
+	FacetKindPackaging    FacetKind = "packaging"
+	FacetKindAssortment   FacetKind = "assortment"
+	FacetKindTasteSymbols FacetKind = "tasteSymbols"
+	// FacetKindOther is used for facets not known by the library.
+	FacetKindOther FacetKind = ""
+)
+
+// facetKinds maps lower case filter names to their kind. Hierarchical filters,
+// such as "categoryLevel1", are matched by their prefix.
+var facetKinds = map[string]FacetKind{
+	"categorylevel":  FacetKindCategory,
+	"country":        FacetKindCountry,
+	"grapes":         FacetKindGrapes,
+	"seal":           FacetKindSeal,
+	"packaginglevel": FacetKindPackaging,
+	"assortmenttext": FacetKindAssortment,
+	"tastesymbols":   FacetKindTasteSymbols,
+}
+
+// parseFacetName returns the kind and level of a filter name, such as
+// "categoryLevel2". The level is 0 for non-hierarchical filters.
+func parseFacetName(name string) (FacetKind, int) {
+	lower := strings.ToLower(name)
+
+	if kind, ok := facetKinds[lower]; ok {
+		return kind, 0
+	}
+
+	trimmed := strings.TrimRight(lower, "0123456789")
+	if trimmed != lower {
+		if kind, ok := facetKinds[trimmed]; ok {
+			level, err := strconv.Atoi(lower[len(trimmed):])
+			if err == nil {
+				return kind, level
+			}
+		}
+	}
+
+	return FacetKindOther, 0
+}
+
+// FacetValue is a value of a [Facet], such as "Öl" for the category facet.
+type FacetValue struct {
+	Value string
+	// Count is the number of products matching the value.
+	Count        int
+	IsActive     bool
+	SubtitleText string
+	FriendlyURL  string
+}
+
+// Facet describes a way to narrow a search, such as by category or country.
+type Facet struct {
+	// Name is the name used by the API, such as "categoryLevel1".
+	Name string
+	Kind FacetKind
+	// Level is the level of hierarchical facets such as categories, starting
+	// at 1. Zero for other facets.
+	Level            int
+	DisplayName      string
+	Description      string
+	IsMultipleChoice bool
+	IsActive         bool
+	Values           []FacetValue
+	// Child is the next level of a hierarchical facet, typically only present
+	// once a value of this facet is active.
+	Child *Facet
+}
+
+// ActiveValues returns the values of the facet that are active.
+func (f *Facet) ActiveValues() []FacetValue {
+	values := make([]FacetValue, 0)
+	for _, value := range f.Values {
+		if value.IsActive {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Value returns the value with the given name, if any.
+func (f *Facet) Value(value string) (FacetValue, bool) {
+	for _, v := range f.Values {
+		if strings.EqualFold(v.Value, value) {
+			return v, true
+		}
+	}
+	return FacetValue{}, false
+}
+
+// Levels returns the facet followed by all of its descendants.
+func (f *Facet) Levels() []*Facet {
+	levels := make([]*Facet, 0)
+	for facet := f; facet != nil; facet = facet.Child {
+		levels = append(levels, facet)
+	}
+	return levels
+}
+
+func newFacet(filter *Filter) *Facet {
+	kind, level := parseFacetName(filter.Name)
+
+	facet := &Facet{
+		Name:             filter.Name,
+		Kind:             kind,
+		Level:            level,
+		DisplayName:      filter.DisplayName,
+		Description:      filter.Description,
+		IsMultipleChoice: filter.IsMultipleChoice,
+		IsActive:         filter.IsActive,
+		Values:           make([]FacetValue, 0, len(filter.SearchModifiers)),
+	}
+
+	for _, modifier := range filter.SearchModifiers {
+		facet.Values = append(facet.Values, FacetValue{
+			Value:        modifier.Value,
+			Count:        modifier.Count,
+			IsActive:     modifier.IsActive,
+			SubtitleText: modifier.SubtitleText,
+			FriendlyURL:  modifier.FriendlyURL,
+		})
+	}
+
+	if filter.Child != nil {
+		facet.Child = newFacet(filter.Child)
+	}
+
+	return facet
+}
+
+// Facets describes the ways a search can be narrowed, see
+// [SearchResult.Facets].
+type Facets struct {
+	facets []*Facet
+}
+
+// Facets interprets the filters of the search result.
+func (r *SearchResult) Facets() *Facets {
+	facets := &Facets{
+		facets: make([]*Facet, 0, len(r.Filters)),
+	}
+
+	for i := range r.Filters {
+		facets.facets = append(facets.facets, newFacet(&r.Filters[i]))
+	}
+
+	return facets
+}
+
+// All returns all top-level facets in the order returned by the API.
+func (f *Facets) All() []*Facet {
+	return f.facets
+}
+
+// Lookup returns a facet by its kind, such as "country", or by its name as
+// used by the API, such as "categoryLevel2". Names are matched case
+// insensitively and hierarchical facets are searched for matching levels.
+func (f *Facets) Lookup(name string) (*Facet, bool) {
+	for _, facet := range f.facets {
+		if facet.Kind != FacetKindOther && strings.EqualFold(string(facet.Kind), name) {
+			return facet, true
+		}
+	}
+
+	for _, facet := range f.facets {
+		for _, level := range facet.Levels() {
+			if strings.EqualFold(level.Name, name) {
+				return level, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Categories returns the top-level category facet. Use [Facet.Child] or
+// [Facet.Levels] for subcategories.
+func (f *Facets) Categories() (*Facet, bool) {
+	return f.Lookup(string(FacetKindCategory))
+}
+
+// Countries returns the country facet.
+func (f *Facets) Countries() (*Facet, bool) {
+	return f.Lookup(string(FacetKindCountry))
+}
+
+// Grapes returns the grapes facet.
+func (f *Facets) Grapes() (*Facet, bool) {
+	return f.Lookup(string(FacetKindGrapes))
+}
+
+// Seals returns the seal facet.
+func (f *Facets) Seals() (*Facet, bool) {
+	return f.Lookup(string(FacetKindSeal))
+}
+
+// Packaging returns the top-level packaging facet.
+func (f *Facets) Packaging() (*Facet, bool) {
+	return f.Lookup(string(FacetKindPackaging))
+}
+
+// Assortments returns the assortment facet.
+func (f *Facets) Assortments() (*Facet, bool) {
+	return f.Lookup(string(FacetKindAssortment))
+}
+
+// TasteSymbols returns the taste symbols facet, such as "Kött".
+func (f *Facets) TasteSymbols() (*Facet, bool) {
+	return f.Lookup(string(FacetKindTasteSymbols))
+}
+
+// parent returns the facet whose child is f, if any.
+func (f *Facets) parent(child *Facet) *Facet {
+	for _, facet := range f.facets {
+		for _, level := range facet.Levels() {
+			if level.Child == child {
+				return level
+			}
+		}
+	}
+	return nil
+}
+
+// chain returns the active values of the ancestors of a hierarchical facet,
+// starting at the top level.
+func (f *Facets) chain(facet *Facet) ([]string, error) {
+	values := make([]string, 0)
+	for parent := f.parent(facet); parent != nil; parent = f.parent(parent) {
+		active := parent.ActiveValues()
+		if len(active) == 0 {
+			return nil, fmt.Errorf("facet %s has no active value", parent.Name)
+		}
+		values = append([]string{active[0].Value}, values...)
+	}
+	return values, nil
+}
+
+// DrillDown returns a [SearchFilter] narrowing a search to the value of the
+// named facet, such as "Ljus lager" for "categoryLevel2". The facet is looked
+// up using [Facets.Lookup].
+//
+// For hierarchical facets, such as categories, the active values of the
+// parent levels are included so that the parent-child chain stays intact.
+func (f *Facets) DrillDown(name string, value string) (SearchFilter, error) {
+	facet, ok := f.Lookup(name)
+	if !ok {
+		return SearchFilter{}, fmt.Errorf("unknown facet: %s", name)
+	}
+
+	if _, ok := facet.Value(value); !ok {
+		return SearchFilter{}, fmt.Errorf("unknown value for facet %s: %s", facet.Name, value)
+	}
+
+	switch facet.Kind {
+	case FacetKindCategory:
+		chain, err := f.chain(facet)
+		if err != nil {
+			return SearchFilter{}, err
+		}
+		chain = append(chain, value)
+
+		if len(chain) > maxFilterArgs {
+			return SearchFilter{}, fmt.Errorf("unsupported category level: %d", len(chain))
+		}
+		return newFilter(FilterKindCategory, chain...), nil
+	case FacetKindPackaging:
+		chain, err := f.chain(facet)
+		if err != nil {
+			return SearchFilter{}, err
+		}
+		chain = append(chain, value)
+
+		if len(chain) > 2 {
+			return SearchFilter{}, fmt.Errorf("unsupported packaging level: %d", len(chain))
+		}
+		return newFilter(FilterKindPackaging, chain...), nil
+	case FacetKindCountry:
+		return FilterByOrigin(value), nil
+	case FacetKindGrapes:
+		return FilterByGrapes(value), nil
+	case FacetKindSeal:
+		return FilterBySeal(value), nil
+	case FacetKindAssortment:
+		return FilterByAssortment(value), nil
+	case FacetKindTasteSymbols:
+		return FilterByMatch(value), nil
+	default:
+		if facet.Name == "" {
+			return SearchFilter{}, fmt.Errorf("unnamed facet")
+		}
+
+		// Unknown facets are assumed to be named as their query parameter
+		parameter := strings.ToLower(facet.Name[:1]) + facet.Name[1:]
+		return FilterByParameter(parameter, value), nil
+	}
+}
+
+// DrillDown returns a [SearchFilter] narrowing a search to a modifier of the
+// named filter. See [Facets.DrillDown].
+func (r *SearchResult) DrillDown(name string, modifier SearchModifier) (SearchFilter, error) {
+	return r.Facets().DrillDown(name, modifier.Value)
+}
@@ -0,0 +1,119 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const facetsSample = `{
+	"filters": [
+		{
+			"name": "CategoryLevel1",
+			"displayName": "Kategori",
+			"isActive": true,
+			"searchModifiers": [
+				{"value": "Öl", "count": 2500, "isActive": true},
+				{"value": "Vin", "count": 9000}
+			],
+			"child": {
+				"name": "CategoryLevel2",
+				"searchModifiers": [
+					{"value": "Ljus lager", "count": 1234}
+				]
+			}
+		},
+		{
+			"name": "Country",
+			"displayName": "Land",
+			"isMultipleChoice": true,
+			"searchModifiers": [
+				{"value": "Sverige", "count": 800, "friendlyUrl": "sverige"}
+			]
+		},
+		{
+			"name": "SomethingNew",
+			"searchModifiers": []
+		}
+	]
+}`
+
+func TestSearchResultFacets(t *testing.T) {
+	var result SearchResult
+	require.NoError(t, json.Unmarshal([]byte(facetsSample), &result))
+
+	facets := result.Facets()
+	assert.Len(t, facets.All(), 3)
+
+	categories, ok := facets.Categories()
+	require.True(t, ok)
+	assert.Equal(t, FacetKindCategory, categories.Kind)
+	assert.Equal(t, 1, categories.Level)
+	assert.Equal(t, []FacetValue{{Value: "Öl", Count: 2500, IsActive: true}}, categories.ActiveValues())
+	require.NotNil(t, categories.Child)
+	assert.Equal(t, 2, categories.Child.Level)
+	assert.Len(t, categories.Levels(), 2)
+
+	subcategories, ok := facets.Lookup("categoryLevel2")
+	require.True(t, ok)
+	assert.Same(t, categories.Child, subcategories)
+
+	countries, ok := facets.Lookup("country")
+	require.True(t, ok)
+	assert.True(t, countries.IsMultipleChoice)
+	value, ok := countries.Value("sverige")
+	require.True(t, ok)
+	assert.Equal(t, 800, value.Count)
+
+	other, ok := facets.Lookup("SomethingNew")
+	require.True(t, ok)
+	assert.Equal(t, FacetKindOther, other.Kind)
+
+	_, ok = facets.Grapes()
+	assert.False(t, ok)
+}
+
+func TestFacetsDrillDown(t *testing.T) {
+	var result SearchResult
+	require.NoError(t, json.Unmarshal([]byte(facetsSample), &result))
+
+	testCases := []struct {
+		Name     string
+		Value    string
+		Expected url.Values
+	}{
+		{
+			Name:     "categoryLevel1",
+			Value:    "Vin",
+			Expected: url.Values{"categoryLevel1": {"Vin"}},
+		},
+		{
+			Name:     "CategoryLevel2",
+			Value:    "Ljus lager",
+			Expected: url.Values{"categoryLevel1": {"Öl"}, "categoryLevel2": {"Ljus lager"}},
+		},
+		{
+			Name:     "country",
+			Value:    "Sverige",
+			Expected: url.Values{"country": {"Sverige"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			filter, err := result.DrillDown(testCase.Name, SearchModifier{Value: testCase.Value})
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.Expected, FiltersToValues(filter))
+		})
+	}
+
+	_, err := result.DrillDown("country", SearchModifier{Value: "Atlantis"})
+	assert.Error(t, err)
+
+	_, err = result.DrillDown("unknown", SearchModifier{Value: "Sverige"})
+	assert.Error(t, err)
+}
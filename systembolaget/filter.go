@@ -0,0 +1,443 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FilterKind identifies what a [SearchFilter] filters by.
+type FilterKind string
+
+const (
+	FilterKindStore                FilterKind = "store"
+	FilterKindQuery                FilterKind = "query"
+	FilterKindTasteClockBody       FilterKind = "body"
+	FilterKindTasteClockBitterness FilterKind = "bitterness"
+	FilterKindTasteClockSweetness  FilterKind = "sweetness"
+	FilterKindTasteClockSmokiness  FilterKind = "smokiness"
+	FilterKindVintage              FilterKind = "vintage"
+	FilterKindProductLaunch        FilterKind = "launched"
+	FilterKindAlcoholPercentage    FilterKind = "abv"
+	FilterKindSugarContent         FilterKind = "sugar"
+	FilterKindGrapes               FilterKind = "grapes"
+	FilterKindMatch                FilterKind = "match"
+	FilterKindAssortment           FilterKind = "assortment"
+	FilterKindSeal                 FilterKind = "seal"
+	FilterKindVolume               FilterKind = "volume"
+	FilterKindPackaging            FilterKind = "packaging"
+	FilterKindPrice                FilterKind = "price"
+	FilterKindOrigin               FilterKind = "origin"
+	FilterKindCategory             FilterKind = "category"
+	// FilterKindParameter sets an arbitrary query parameter, see
+	// [FilterByParameter].
+	FilterKindParameter FilterKind = "param"
+	// FilterKindFunc modifies the query parameters using a custom function, see
+	// [FilterFunc].
+	FilterKindFunc FilterKind = "func"
+)
+
+// filterShape describes how the arguments of a filter map to query
+// parameters.
+type filterShape int
+
+const (
+	// shapeSet sets a single parameter.
+	shapeSet filterShape = iota
+	// shapeAdd adds a single parameter, allowing the filter to be used more than
+	// once.
+	shapeAdd
+	// shapeRange sets the ".min" and ".max" parameters. Either may be empty to
+	// leave the range open.
+	shapeRange
+	// shapeLevels sets a hierarchy of parameters, such as "categoryLevel1"
+	// through "categoryLevel4". Levels below the second are added.
+	shapeLevels
+	// shapeStore sets the store parameters.
+	shapeStore
+	// shapeParameter adds the parameter named by the first argument.
+	shapeParameter
+)
+
+type filterSpec struct {
+	kind      FilterKind
+	shape     filterShape
+	parameter string
+	// maxArgs is the maximum number of arguments.
+	maxArgs int
+}
+
+// filterSpecs lists known filters in the order they are identified by
+// [FiltersFromValues].
+var filterSpecs = []filterSpec{
+	{kind: FilterKindStore, shape: shapeStore, parameter: "storeId", maxArgs: 1},
+	{kind: FilterKindQuery, shape: shapeSet, parameter: "textQuery", maxArgs: 1},
+	{kind: FilterKindCategory, shape: shapeLevels, parameter: "categoryLevel", maxArgs: 4},
+	{kind: FilterKindPackaging, shape: shapeLevels, parameter: "packagingLevel", maxArgs: 2},
+	{kind: FilterKindOrigin, shape: shapeAdd, parameter: "country", maxArgs: 1},
+	{kind: FilterKindPrice, shape: shapeRange, parameter: "price", maxArgs: 2},
+	{kind: FilterKindVolume, shape: shapeRange, parameter: "volume", maxArgs: 2},
+	{kind: FilterKindAlcoholPercentage, shape: shapeRange, parameter: "alcoholPercentage", maxArgs: 2},
+	{kind: FilterKindSugarContent, shape: shapeRange, parameter: "sugarContentGramPer100ml", maxArgs: 2},
+	{kind: FilterKindProductLaunch, shape: shapeRange, parameter: "productLaunch", maxArgs: 2},
+	{kind: FilterKindTasteClockBody, shape: shapeRange, parameter: "tasteClockBody", maxArgs: 2},
+	{kind: FilterKindTasteClockBitterness, shape: shapeRange, parameter: "tasteClockBitter", maxArgs: 2},
+	{kind: FilterKindTasteClockSweetness, shape: shapeRange, parameter: "tasteClockSweetness", maxArgs: 2},
+	{kind: FilterKindTasteClockSmokiness, shape: shapeRange, parameter: "tasteClockSmokiness", maxArgs: 2},
+	{kind: FilterKindVintage, shape: shapeAdd, parameter: "vintage", maxArgs: 1},
+	{kind: FilterKindGrapes, shape: shapeAdd, parameter: "grapes", maxArgs: 1},
+	{kind: FilterKindMatch, shape: shapeAdd, parameter: "tasteSymbols", maxArgs: 1},
+	{kind: FilterKindAssortment, shape: shapeAdd, parameter: "assortmentText", maxArgs: 1},
+	{kind: FilterKindSeal, shape: shapeAdd, parameter: "seal", maxArgs: 1},
+	{kind: FilterKindParameter, shape: shapeParameter, maxArgs: 2},
+}
+
+func lookupFilterSpec(kind FilterKind) (filterSpec, bool) {
+	for _, spec := range filterSpecs {
+		if spec.kind == kind {
+			return spec, true
+		}
+	}
+	return filterSpec{}, false
+}
+
+// optionParameters are query parameters controlled by [SearchOptions] rather
+// than filters.
+var optionParameters = []string{"size", "page", "sortBy", "sortDirection"}
+
+// maxFilterArgs is the maximum number of arguments of any filter.
+const maxFilterArgs = 4
+
+// SearchFilter describes a filter that modifies the search query.
+//
+// Filters are comparable values, so they may be used as map keys. They can be
+// printed, see [SearchFilter.String], serialized as JSON and converted to and
+// from query parameters, see [FiltersToValues] and [FiltersFromValues].
+// Filters created using [FilterFunc] are the exception, as their function
+// can't be represented as text.
+type SearchFilter struct {
+	kind FilterKind
+	args [maxFilterArgs]string
+	// fn is the function of a filter created using [FilterFunc]. It's a
+	// pointer to keep filters comparable.
+	fn *func(*url.Values)
+}
+
+func newFilter(kind FilterKind, args ...string) SearchFilter {
+	filter := SearchFilter{kind: kind}
+	copy(filter.args[:], args)
+	return filter
+}
+
+// NewSearchFilter returns a filter of the given kind with the given
+// arguments, as returned by [SearchFilter.Args].
+func NewSearchFilter(kind FilterKind, args ...string) (SearchFilter, error) {
+	spec, ok := lookupFilterSpec(kind)
+	if !ok {
+		return SearchFilter{}, fmt.Errorf("unknown filter kind: %s", kind)
+	}
+
+	if len(args) == 0 || len(args) > spec.maxArgs {
+		return SearchFilter{}, fmt.Errorf("invalid number of arguments for filter %s: %d", kind, len(args))
+	}
+
+	if args[0] == "" && spec.shape != shapeRange {
+		return SearchFilter{}, fmt.Errorf("missing value for filter %s", kind)
+	}
+
+	if spec.shape == shapeParameter && len(args) != 2 {
+		return SearchFilter{}, fmt.Errorf("invalid number of arguments for filter %s: %d", kind, len(args))
+	}
+
+	if spec.shape == shapeRange && (len(args) != 2 || (args[0] == "" && args[1] == "")) {
+		return SearchFilter{}, fmt.Errorf("invalid range for filter %s", kind)
+	}
+
+	return newFilter(kind, args...), nil
+}
+
+// Kind returns what the filter filters by.
+func (f SearchFilter) Kind() FilterKind {
+	return f.kind
+}
+
+// Args returns the arguments of the filter, such as the category hierarchy of
+// a category filter or the minimum and maximum of a range filter. Open ends of
+// ranges and empty parameter values are kept as empty arguments.
+func (f SearchFilter) Args() []string {
+	spec, _ := lookupFilterSpec(f.kind)
+	switch spec.shape {
+	case shapeRange, shapeParameter:
+		return []string{f.args[0], f.args[1]}
+	}
+
+	n := len(f.args)
+	for n > 0 && f.args[n-1] == "" {
+		n--
+	}
+	return slices.Clone(f.args[:n])
+}
+
+// Apply adds the filter's query parameters to v.
+func (f SearchFilter) Apply(v *url.Values) {
+	if f.fn != nil {
+		(*f.fn)(v)
+		return
+	}
+
+	spec, ok := lookupFilterSpec(f.kind)
+	if !ok {
+		return
+	}
+
+	switch spec.shape {
+	case shapeSet:
+		v.Set(spec.parameter, f.args[0])
+	case shapeAdd:
+		v.Add(spec.parameter, f.args[0])
+	case shapeRange:
+		if f.args[0] != "" {
+			v.Set(spec.parameter+".min", f.args[0])
+		}
+		if f.args[1] != "" {
+			v.Set(spec.parameter+".max", f.args[1])
+		}
+	case shapeLevels:
+		for i, arg := range f.args[:spec.maxArgs] {
+			if arg == "" {
+				break
+			}
+
+			parameter := spec.parameter + strconv.Itoa(i+1)
+			if i < 2 {
+				v.Set(parameter, arg)
+			} else {
+				v.Add(parameter, arg)
+			}
+		}
+	case shapeStore:
+		v.Set(spec.parameter, f.args[0])
+		v.Set("isInStoreAssortmentSearch", "true")
+	case shapeParameter:
+		v.Add(f.args[0], f.args[1])
+	}
+}
+
+// String returns a textual representation of the filter, such as
+// `category:Öl/"Ljus lager"` or `price:10..40`. Filters created using
+// [FilterFunc] have no textual representation, so the empty string is
+// returned.
+func (f SearchFilter) String() string {
+	spec, ok := lookupFilterSpec(f.kind)
+	if !ok {
+		return ""
+	}
+
+	args := f.Args()
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quoteFilterValue(arg))
+	}
+
+	// Filters such as FilterByQuery("") have no non-empty arguments
+	if len(quoted) == 0 {
+		quoted = append(quoted, quoteFilterValue(""))
+	}
+
+	switch spec.shape {
+	case shapeRange:
+		return string(f.kind) + ":" + args[0] + ".." + args[1]
+	case shapeLevels:
+		return string(f.kind) + ":" + strings.Join(quoted, "/")
+	case shapeParameter:
+		return string(f.kind) + ":" + strings.Join(quoted, "=")
+	default:
+		return string(f.kind) + ":" + quoted[0]
+	}
+}
+
+// quoteFilterValue quotes a value if it's empty or contains whitespace or
+// characters with a special meaning in a filter's textual representation.
+func quoteFilterValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"\\/=:") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+type searchFilterJSON struct {
+	Kind FilterKind `json:"kind"`
+	Args []string   `json:"args"`
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (f SearchFilter) MarshalJSON() ([]byte, error) {
+	if f.fn != nil {
+		return nil, fmt.Errorf("filter %s can't be serialized", f.kind)
+	}
+
+	return json.Marshal(searchFilterJSON{
+		Kind: f.kind,
+		Args: f.Args(),
+	})
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (f *SearchFilter) UnmarshalJSON(data []byte) error {
+	var value searchFilterJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	filter, err := NewSearchFilter(value.Kind, value.Args...)
+	if err != nil {
+		return err
+	}
+
+	*f = filter
+	return nil
+}
+
+// FiltersToValues returns the query parameters of the filters.
+func FiltersToValues(filters ...SearchFilter) url.Values {
+	values := url.Values{}
+	for _, filter := range filters {
+		filter.Apply(&values)
+	}
+	return values
+}
+
+// FiltersFromValues returns filters equivalent to the query parameters, such
+// that [FiltersToValues] returns the same parameters. Parameters of
+// [SearchOptions], such as "page", are ignored. Unknown parameters, and values
+// that can't be expressed using the filter of their parameter, such as a
+// second "textQuery", are kept using [FilterByParameter].
+func FiltersFromValues(values url.Values) []SearchFilter {
+	// Work on a copy to keep track of remaining parameters
+	remaining := make(url.Values, len(values))
+	for key, v := range values {
+		remaining[key] = slices.Clone(v)
+	}
+
+	for _, parameter := range optionParameters {
+		remaining.Del(parameter)
+	}
+
+	filters := make([]SearchFilter, 0)
+	for _, spec := range filterSpecs {
+		switch spec.shape {
+		case shapeSet:
+			// Setting the parameter again would replace the value
+			if value, ok := takeValue(remaining, spec.parameter); ok {
+				filters = append(filters, newFilter(spec.kind, value))
+			}
+		case shapeAdd:
+			for {
+				value, ok := takeValue(remaining, spec.parameter)
+				if !ok {
+					break
+				}
+				filters = append(filters, newFilter(spec.kind, value))
+			}
+		case shapeRange:
+			// Empty values would be interpreted as open ends
+			minimum, hasMinimum := takeNonEmptyValue(remaining, spec.parameter+".min")
+			maximum, hasMaximum := takeNonEmptyValue(remaining, spec.parameter+".max")
+			if hasMinimum || hasMaximum {
+				filters = append(filters, newFilter(spec.kind, minimum, maximum))
+			}
+		case shapeLevels:
+			// The first two levels are set and must form a chain, which ends at
+			// the first empty level
+			chain := make([]string, 0, spec.maxArgs)
+			for i := range min(spec.maxArgs, 2) {
+				value, ok := takeNonEmptyValue(remaining, spec.parameter+strconv.Itoa(i+1))
+				if !ok {
+					break
+				}
+				chain = append(chain, value)
+			}
+
+			if len(chain) == 0 {
+				continue
+			}
+
+			// Each subsubcategory is expressed as one filter, which also adds the
+			// level below it, if any
+			levels := make([][]string, 0)
+			if len(chain) == 2 && spec.maxArgs > 2 {
+				for {
+					value, ok := takeNonEmptyValue(remaining, spec.parameter+"3")
+					if !ok {
+						break
+					}
+					levels = append(levels, append(slices.Clone(chain), value))
+				}
+			}
+
+			if spec.maxArgs > 3 {
+				for i := range levels {
+					value, ok := takeNonEmptyValue(remaining, spec.parameter+"4")
+					if !ok {
+						break
+					}
+					levels[i] = append(levels[i], value)
+				}
+			}
+
+			if len(levels) == 0 {
+				levels = append(levels, chain)
+			}
+
+			for _, level := range levels {
+				filters = append(filters, newFilter(spec.kind, level...))
+			}
+		case shapeStore:
+			// The filter also sets isInStoreAssortmentSearch
+			if !slices.Equal(remaining["isInStoreAssortmentSearch"], []string{"true"}) {
+				continue
+			}
+
+			if value, ok := takeValue(remaining, spec.parameter); ok {
+				filters = append(filters, newFilter(spec.kind, value))
+				remaining.Del("isInStoreAssortmentSearch")
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(remaining)) {
+		for _, value := range remaining[key] {
+			filters = append(filters, FilterByParameter(key, value))
+		}
+	}
+
+	return filters
+}
+
+// takeValue removes and returns the first value of key.
+func takeValue(values url.Values, key string) (string, bool) {
+	v := values[key]
+	if len(v) == 0 {
+		return "", false
+	}
+
+	if len(v) == 1 {
+		delete(values, key)
+	} else {
+		values[key] = v[1:]
+	}
+	return v[0], true
+}
+
+// takeNonEmptyValue removes and returns the first value of key, unless it's
+// empty.
+func takeNonEmptyValue(values url.Values, key string) (string, bool) {
+	if len(values[key]) == 0 || values[key][0] == "" {
+		return "", false
+	}
+	return takeValue(values, key)
+}
@@ -0,0 +1,147 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFilterValues(t *testing.T) {
+	filters := []SearchFilter{
+		FilterByStore("0102"),
+		FilterByQuery("pilsner"),
+		FilterByCategory("Öl", "Ljus lager", "Pilsner - tysk stil"),
+		FilterByPackaging("Flaska", "Glasflaska"),
+		FilterByOrigin("Sverige"),
+		FilterByOrigin("Tyskland"),
+		FilterByPrice(10, 40),
+		FilterByAlcoholPercentage(0, 5),
+		FilterBySugarContent(0, 2.5),
+		FilterByProductLaunch(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)),
+		FilterByTasteClockBody(3, 9),
+		FilterByVintage(2019),
+		FilterByGrapes("Riesling"),
+		FilterByMatch("Kött"),
+		FilterByAssortment("Fast sortiment"),
+		FilterBySeal("A-koppling"),
+		FilterByParameter("somethingNew", "value"),
+	}
+
+	values := FiltersToValues(filters...)
+	assert.Equal(t, "0102", values.Get("storeId"))
+	assert.Equal(t, "true", values.Get("isInStoreAssortmentSearch"))
+	assert.Equal(t, []string{"Sverige", "Tyskland"}, values["country"])
+	assert.Equal(t, "2.50", values.Get("sugarContentGramPer100ml.max"))
+	assert.Equal(t, "2025-01-01", values.Get("productLaunch.min"))
+
+	roundTripped := FiltersFromValues(values)
+	assert.ElementsMatch(t, filters, roundTripped)
+	assert.Equal(t, values, FiltersToValues(roundTripped...))
+}
+
+func TestFiltersFromValuesMultipleSubsubcategories(t *testing.T) {
+	values := url.Values{
+		"categoryLevel1": {"Öl"},
+		"categoryLevel2": {"Ljus lager"},
+		"categoryLevel3": {"Pilsner - tysk stil", "Internationell stil"},
+		"page":           {"2"},
+	}
+
+	filters := FiltersFromValues(values)
+	assert.Equal(t, []SearchFilter{
+		FilterByCategory("Öl", "Ljus lager", "Pilsner - tysk stil"),
+		FilterByCategory("Öl", "Ljus lager", "Internationell stil"),
+	}, filters)
+}
+
+func TestFiltersFromValuesRoundTrip(t *testing.T) {
+	testCases := []url.Values{
+		{"categoryLevel2": {"Ljus lager"}},
+		{"categoryLevel1": {"Öl", "Vin"}, "categoryLevel2": {"Ljus lager"}},
+		{"categoryLevel1": {"Öl"}, "categoryLevel2": {"Ljus lager"}, "categoryLevel3": {"a", "b"}, "categoryLevel4": {"c"}},
+		{"categoryLevel1": {"Öl"}, "categoryLevel2": {"Ljus lager"}, "categoryLevel3": {"a"}, "categoryLevel4": {"c", "d"}},
+		{"categoryLevel1": {"Öl"}, "categoryLevel2": {""}, "categoryLevel3": {"a"}},
+		{"categoryLevel1": {"Öl"}, "categoryLevel4": {"c"}},
+		{"packagingLevel1": {"Flaska"}, "packagingLevel2": {"Glasflaska", "Plastflaska"}},
+		{"price.min": {"10", "20"}, "price.max": {"40"}},
+		{"price.min": {""}},
+		{"textQuery": {"a", "b"}},
+		{"textQuery": {""}},
+		{"storeId": {"1"}, "isInStoreAssortmentSearch": {"false"}},
+		{"storeId": {"1"}},
+		{"storeId": {"1", "2"}, "isInStoreAssortmentSearch": {"true"}},
+		{"isInStoreAssortmentSearch": {"true"}},
+		{"country": {"Sverige", ""}, "somethingNew": {"a", "b"}},
+	}
+
+	for _, values := range testCases {
+		t.Run(values.Encode(), func(t *testing.T) {
+			assert.Equal(t, values, FiltersToValues(FiltersFromValues(values)...))
+		})
+	}
+}
+
+func TestSearchFilterString(t *testing.T) {
+	assert.Equal(t, `category:Öl/"Ljus lager"`, FilterByCategory("Öl", "Ljus lager", "").String())
+	assert.Equal(t, `origin:Sverige`, FilterByOrigin("Sverige").String())
+	assert.Equal(t, `price:10..40`, FilterByPrice(10, 40).String())
+	assert.Equal(t, `query:"a \"quoted\" text"`, FilterByQuery(`a "quoted" text`).String())
+	assert.Equal(t, `param:somethingNew=value`, FilterByParameter("somethingNew", "value").String())
+}
+
+func TestSearchFilterStringEmpty(t *testing.T) {
+	assert.Equal(t, `query:""`, FilterByQuery("").String())
+	assert.Equal(t, `category:""`, FilterByCategory("", "", "").String())
+	assert.Equal(t, `param:somethingNew=""`, FilterByParameter("somethingNew", "").String())
+	assert.Equal(t, `[query:""]`, fmt.Sprintf("%v", FiltersFromValues(url.Values{"textQuery": {""}})))
+
+	for _, filter := range []SearchFilter{FilterByQuery(""), FilterByParameter("somethingNew", "")} {
+		query, err := ParseQuery(filter.String())
+		require.NoError(t, err)
+		assert.Equal(t, []SearchFilter{filter}, query.Filters)
+	}
+}
+
+func TestSearchFilterJSON(t *testing.T) {
+	filter := FilterByCategory("Öl", "Ljus lager", "")
+
+	data, err := json.Marshal(filter)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"category","args":["Öl","Ljus lager"]}`, string(data))
+
+	var decoded SearchFilter
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, filter, decoded)
+
+	// Filters are comparable
+	seen := map[SearchFilter]bool{filter: true}
+	assert.True(t, seen[decoded])
+
+	assert.Error(t, json.Unmarshal([]byte(`{"kind":"unknown","args":["x"]}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"kind":"price","args":["1"]}`), &decoded))
+}
+
+func TestFilterFunc(t *testing.T) {
+	filter := FilterFunc(func(v *url.Values) {
+		v.Set("textQuery", "replaced")
+		v.Del("country")
+	})
+	assert.Equal(t, FilterKindFunc, filter.Kind())
+
+	values := FiltersToValues(FilterByQuery("original"), FilterByOrigin("Sverige"), filter)
+	assert.Equal(t, url.Values{"textQuery": {"replaced"}}, values)
+
+	// Filters are only equal to themselves
+	seen := map[SearchFilter]bool{filter: true}
+	assert.True(t, seen[filter])
+	assert.False(t, seen[FilterFunc(func(v *url.Values) {})])
+
+	assert.Empty(t, filter.String())
+	_, err := json.Marshal(filter)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package systembolaget
+
+import (
+	"math"
+	"slices"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Distance returns the great-circle distance between two positions in meters,
+// using the haversine formula.
+func Distance(a StorePosition, b StorePosition) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	deltaLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	deltaLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// StoreDistance is a store and its distance from a position.
+type StoreDistance struct {
+	Store
+	// Distance is the distance in meters.
+	Distance float64 `json:"distance"`
+}
+
+// storeDistances returns the distance of each store with a position from the
+// given coordinates, nearest first.
+func storeDistances(stores []Store, latitude float64, longitude float64) []StoreDistance {
+	origin := StorePosition{Latitude: latitude, Longitude: longitude}
+
+	distances := make([]StoreDistance, 0, len(stores))
+	for _, store := range stores {
+		if store.Position == nil {
+			continue
+		}
+
+		distances = append(distances, StoreDistance{
+			Store:    store,
+			Distance: Distance(origin, *store.Position),
+		})
+	}
+
+	slices.SortStableFunc(distances, func(a StoreDistance, b StoreDistance) int {
+		switch {
+		case a.Distance < b.Distance:
+			return -1
+		case a.Distance > b.Distance:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return distances
+}
+
+// NearestStores returns the n stores nearest to the given coordinates, nearest
+// first. A non-positive n returns all stores. Stores without a position are
+// excluded.
+func NearestStores(stores []Store, latitude float64, longitude float64, n int) []StoreDistance {
+	distances := storeDistances(stores, latitude, longitude)
+	if n > 0 && n < len(distances) {
+		distances = distances[:n]
+	}
+	return distances
+}
+
+// StoresWithinRadius returns the stores within radius meters of the given
+// coordinates, nearest first. Stores without a position are excluded.
+func StoresWithinRadius(stores []Store, latitude float64, longitude float64, radius float64) []StoreDistance {
+	distances := storeDistances(stores, latitude, longitude)
+	end := len(distances)
+	for i, distance := range distances {
+		if distance.Distance > radius {
+			end = i
+			break
+		}
+	}
+	return distances[:end]
+}
@@ -0,0 +1,43 @@
+package systembolaget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	stockholm := StorePosition{Latitude: 59.3293, Longitude: 18.0686}
+	gothenburg := StorePosition{Latitude: 57.7089, Longitude: 11.9746}
+
+	assert.InDelta(t, 398_000, Distance(stockholm, gothenburg), 2_000)
+	assert.InDelta(t, Distance(stockholm, gothenburg), Distance(gothenburg, stockholm), 0.001)
+	assert.Zero(t, Distance(stockholm, stockholm))
+}
+
+func TestNearestStores(t *testing.T) {
+	stores := []Store{
+		{SiteID: "far", Position: &StorePosition{Latitude: 59.3293, Longitude: 18.0686}},
+		{SiteID: "unknown"},
+		{SiteID: "near", Position: &StorePosition{Latitude: 57.7000, Longitude: 11.9700}},
+		{SiteID: "nearby", Position: &StorePosition{Latitude: 57.7200, Longitude: 11.9400}},
+	}
+
+	nearest := NearestStores(stores, 57.70, 11.97, 2)
+	if assert.Len(t, nearest, 2) {
+		assert.Equal(t, "near", nearest[0].SiteID)
+		assert.InDelta(t, 0, nearest[0].Distance, 1)
+		assert.Equal(t, "nearby", nearest[1].SiteID)
+		assert.InDelta(t, 2_900, nearest[1].Distance, 100)
+	}
+
+	assert.Len(t, NearestStores(stores, 57.70, 11.97, 0), 3)
+
+	within := StoresWithinRadius(stores, 57.70, 11.97, 5_000)
+	if assert.Len(t, within, 2) {
+		assert.Equal(t, "near", within[0].SiteID)
+		assert.Equal(t, "nearby", within[1].SiteID)
+	}
+
+	assert.Empty(t, StoresWithinRadius(stores, 0, 0, 5_000))
+}
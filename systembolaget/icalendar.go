@@ -0,0 +1,185 @@
+package systembolaget
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// stockholmVTimezone describes the [Stockholm] time zone in iCalendar format.
+var stockholmVTimezone = []string{
+	"BEGIN:VTIMEZONE",
+	"TZID:Europe/Stockholm",
+	"BEGIN:DAYLIGHT",
+	"TZOFFSETFROM:+0100",
+	"TZOFFSETTO:+0200",
+	"TZNAME:CEST",
+	"DTSTART:19700329T020000",
+	"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+	"END:DAYLIGHT",
+	"BEGIN:STANDARD",
+	"TZOFFSETFROM:+0200",
+	"TZOFFSETTO:+0100",
+	"TZNAME:CET",
+	"DTSTART:19701025T030000",
+	"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+	"END:STANDARD",
+	"END:VTIMEZONE",
+}
+
+// WriteICalendar writes the opening hours of the stores as an iCalendar
+// (RFC 5545) calendar.
+//
+// Each day the store is open becomes an event from opening to closing, in the
+// Europe/Stockholm time zone. Each day the store is closed becomes an all-day
+// event. Events on days with special opening hours, such as holidays, include
+// the reason in their summary and are categorized as such.
+func WriteICalendar(w io.Writer, stores ...Store) error {
+	writer := &icalendarWriter{writer: bufio.NewWriter(w)}
+
+	name := "Systembolaget"
+	if len(stores) == 1 {
+		name = "Systembolaget " + storeName(stores[0])
+	}
+
+	writer.line("BEGIN:VCALENDAR")
+	writer.line("VERSION:2.0")
+	writer.line("PRODID:-//systembolaget-api//Opening hours//EN")
+	writer.line("CALSCALE:GREGORIAN")
+	writer.line("METHOD:PUBLISH")
+	writer.property("X-WR-CALNAME", name)
+	writer.line("X-WR-TIMEZONE:Europe/Stockholm")
+	for _, line := range stockholmVTimezone {
+		writer.line(line)
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, store := range stores {
+		for _, hours := range store.ParsedOpeningHours() {
+			writer.event(store, hours, stamp)
+		}
+	}
+
+	writer.line("END:VCALENDAR")
+
+	if writer.err != nil {
+		return writer.err
+	}
+	return writer.writer.Flush()
+}
+
+// storeName returns the name of a store, such as "Fältöversten".
+func storeName(store Store) string {
+	switch {
+	case store.DisplayName != "":
+		return store.DisplayName
+	case store.Alias != "":
+		return store.Alias
+	default:
+		return store.SiteID
+	}
+}
+
+// icalendarWriter writes iCalendar content lines, keeping the first error.
+type icalendarWriter struct {
+	writer *bufio.Writer
+	err    error
+}
+
+// event writes the event of a store's opening hours on a single day.
+func (w *icalendarWriter) event(store Store, hours OpeningHours, stamp string) {
+	summary := storeName(store)
+	if hours.IsClosed() {
+		summary += " closed"
+	} else {
+		summary += " open"
+	}
+	if hours.IsSpecialDay() {
+		summary += " (" + hours.Reason + ")"
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.property("UID", fmt.Sprintf("%s-%s@systembolaget.se", store.SiteID, hours.Date.Format("20060102")))
+	w.line("DTSTAMP:" + stamp)
+	if hours.IsClosed() {
+		w.line("DTSTART;VALUE=DATE:" + hours.Date.Format("20060102"))
+		w.line("DTEND;VALUE=DATE:" + hours.Date.AddDate(0, 0, 1).Format("20060102"))
+	} else {
+		w.line("DTSTART;TZID=Europe/Stockholm:" + hours.Opens.In(Stockholm).Format("20060102T150405"))
+		w.line("DTEND;TZID=Europe/Stockholm:" + hours.Closes.In(Stockholm).Format("20060102T150405"))
+	}
+	w.property("SUMMARY", summary)
+	if hours.IsSpecialDay() {
+		w.property("DESCRIPTION", hours.Reason)
+		w.line("CATEGORIES:SPECIAL OPENING HOURS")
+	}
+	if location := storeLocation(store); location != "" {
+		w.property("LOCATION", location)
+	}
+	if store.Position != nil {
+		w.line(fmt.Sprintf("GEO:%f;%f", store.Position.Latitude, store.Position.Longitude))
+	}
+	w.line("TRANSP:TRANSPARENT")
+	w.line("END:VEVENT")
+}
+
+// storeLocation returns the address of a store, such as
+// "Karlaplan 13, Stockholm".
+func storeLocation(store Store) string {
+	parts := make([]string, 0, 2)
+	if store.StreetAddress != "" {
+		parts = append(parts, store.StreetAddress)
+	}
+	if store.City != "" {
+		// Cities are typically upper case
+		parts = append(parts, titleCase(store.City))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// titleCase returns s in lower case with the first letter of each word in
+// upper case, such as "Stockholm" for "STOCKHOLM".
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = strings.ToUpper(string(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// property writes a property with an escaped text value.
+func (w *icalendarWriter) property(name string, value string) {
+	value = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+	w.line(name + ":" + value)
+}
+
+// line writes a content line, folding it at 75 octets as required by
+// RFC 5545 without splitting multi-byte characters.
+func (w *icalendarWriter) line(line string) {
+	if w.err != nil {
+		return
+	}
+
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		_, w.err = w.writer.WriteString(line[:cut] + "\r\n ")
+		if w.err != nil {
+			return
+		}
+
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+
+	_, w.err = w.writer.WriteString(line + "\r\n")
+}
@@ -0,0 +1,94 @@
+package systembolaget
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	content, err := os.ReadFile("../samples/stores.json")
+	require.NoError(t, err)
+
+	var store Store
+	require.NoError(t, json.Unmarshal(content, &store))
+	store.OpeningHours = append(store.OpeningHours, StoreOpeningHours{
+		Date:     "2024-04-30T00:00:00",
+		OpenFrom: "10:00:00",
+		OpenTo:   "15:00:00",
+		Reason:   "Valborgsmässoafton",
+	})
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteICalendar(&buffer, store))
+	calendar := buffer.String()
+
+	// Lines are terminated by CRLF and folded at 75 octets
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	for line := range strings.SplitSeq(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+		assert.NotContains(t, line, "\n")
+	}
+
+	unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+	assert.Regexp(t, `DTSTAMP:\d{8}T\d{6}Z\r\n`, unfolded)
+	unfolded = regexp.MustCompile(`DTSTAMP:.*\r\n`).ReplaceAllString(unfolded, "")
+
+	assert.Contains(t, unfolded, "X-WR-CALNAME:Systembolaget Fältöversten\r\n")
+	assert.Contains(t, unfolded, "TZID:Europe/Stockholm\r\n")
+	assert.Equal(t, len(store.OpeningHours), strings.Count(unfolded, "BEGIN:VEVENT\r\n"))
+
+	assert.Contains(t, unfolded, strings.Join([]string{
+		"UID:0102-20240502@systembolaget.se",
+		"DTSTART;TZID=Europe/Stockholm:20240502T100000",
+		"DTEND;TZID=Europe/Stockholm:20240502T190000",
+		"SUMMARY:Fältöversten open",
+		"LOCATION:Karlaplan 13\\, Stockholm",
+	}, "\r\n"))
+
+	// Regular closed days
+	assert.Contains(t, unfolded, strings.Join([]string{
+		"DTSTART;VALUE=DATE:20240505",
+		"DTEND;VALUE=DATE:20240506",
+		"SUMMARY:Fältöversten closed\r\n",
+	}, "\r\n"))
+
+	// Holidays
+	assert.Contains(t, unfolded, strings.Join([]string{
+		"DTSTART;VALUE=DATE:20240509",
+		"DTEND;VALUE=DATE:20240510",
+		"SUMMARY:Fältöversten closed (Kristi Himmelfärdsdag)",
+		"DESCRIPTION:Kristi Himmelfärdsdag",
+		"CATEGORIES:SPECIAL OPENING HOURS",
+	}, "\r\n"))
+
+	// Special opening hours
+	assert.Contains(t, unfolded, strings.Join([]string{
+		"DTSTART;TZID=Europe/Stockholm:20240430T100000",
+		"DTEND;TZID=Europe/Stockholm:20240430T150000",
+		"SUMMARY:Fältöversten open (Valborgsmässoafton)",
+	}, "\r\n"))
+}
+
+func TestICalendarWriterLine(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := &icalendarWriter{writer: bufio.NewWriter(&buffer)}
+	writer.property("DESCRIPTION", strings.Repeat("ö", 100)+"; a, b\\c\nd")
+	require.NoError(t, writer.writer.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n")
+	assert.Len(t, lines, 4)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	unfolded := strings.ReplaceAll(buffer.String(), "\r\n ", "")
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("ö", 100)+`\; a\, b\\c\nd`+"\r\n", unfolded)
+}
@@ -0,0 +1,79 @@
+package systembolaget
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Pages returns an iterator over the pages of a search, starting at
+// options.Page. Pages are fetched lazily, waiting options.PageDelay between
+// each page. The iteration stops after the last page, when the context is
+// cancelled or after the first error, which is yielded with a nil page.
+func (c *AuthenticatedClient) Pages(ctx context.Context, options *SearchOptions, filters ...SearchFilter) iter.Seq2[*SearchResult, error] {
+	var opts SearchOptions
+	if options != nil {
+		opts = *options
+	}
+
+	return func(yield func(*SearchResult, error) bool) {
+		// Don't modify the options shared between iterations
+		options := opts
+		if options.Page < 1 {
+			options.Page = 1
+		}
+
+		for first := true; ; first = false {
+			if !first && options.PageDelay > 0 {
+				select {
+				case <-time.After(options.PageDelay):
+				case <-ctx.Done():
+					yield(nil, ctx.Err())
+					return
+				}
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page, err := c.Search(ctx, &options, filters...)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(page, nil) {
+				return
+			}
+
+			// NextPage is -1 if there are no more pages
+			if page.Metadata.NextPage <= options.Page || len(page.Products) == 0 {
+				return
+			}
+
+			options.Page = page.Metadata.NextPage
+		}
+	}
+}
+
+// Products returns an iterator over the products of a search. See
+// [AuthenticatedClient.Pages] for how pages are fetched. If an error occurs, it
+// is yielded with a nil product and the iteration stops.
+func (c *AuthenticatedClient) Products(ctx context.Context, options *SearchOptions, filters ...SearchFilter) iter.Seq2[Product, error] {
+	return func(yield func(Product, error) bool) {
+		for page, err := range c.Pages(ctx, options, filters...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, product := range page.Products {
+				if !yield(product, nil) {
+					return
+				}
+			}
+		}
+	}
+}
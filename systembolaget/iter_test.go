@@ -0,0 +1,85 @@
+package systembolaget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagedServer returns a server serving three pages of two products each.
+func newPagedServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		nextPage := page + 1
+		if page >= 3 {
+			nextPage = -1
+		}
+		fmt.Fprintf(w, `{"metadata":{"nextPage":%d},"products":[{"productId":"%d-1"},{"productId":"%d-2"}]}`, nextPage, page, page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthenticatedClientProducts(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedServer(t, &requests)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	ids := make([]string, 0)
+	for product, err := range client.Products(context.TODO(), nil) {
+		require.NoError(t, err)
+		id, _ := product.ID()
+		ids = append(ids, id)
+	}
+
+	assert.Equal(t, []string{"1-1", "1-2", "2-1", "2-2", "3-1", "3-2"}, ids)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestAuthenticatedClientProductsBreak(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedServer(t, &requests)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	for product, err := range client.Products(context.TODO(), &SearchOptions{Page: 2}) {
+		require.NoError(t, err)
+		id, _ := product.ID()
+		assert.Equal(t, "2-1", id)
+		break
+	}
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestAuthenticatedClientPagesCancelled(t *testing.T) {
+	var requests atomic.Int32
+	server := newPagedServer(t, &requests)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	pages := 0
+	var lastErr error
+	for page, err := range client.Pages(ctx, nil) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		assert.NotNil(t, page)
+		pages++
+		cancel()
+	}
+
+	assert.Equal(t, 1, pages)
+	assert.ErrorIs(t, lastErr, context.Canceled)
+}
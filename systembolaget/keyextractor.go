@@ -0,0 +1,496 @@
+package systembolaget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// apiKeyRegex matches the API key in scripts, JSON and escaped JSON, such as
+// NEXT_PUBLIC_API_KEY_APIM:"..." and "NEXT_PUBLIC_API_KEY_APIM":"...".
+var apiKeyRegex = regexp.MustCompile(`NEXT_PUBLIC_API_KEY_APIM(?:\\?")?\s*:\s*\\?"([^"\\]+)\\?"`)
+
+// <script src="/_next/static/chunks/131pjojsj1pi9.js" defer=""></script>
+var chunkPathRegex = regexp.MustCompile(`src="(/_next/static/chunks/[^"]+\.js)"`)
+
+// <script src="/_next/static/mXn2C3tHQpi_TGDh1yc0H/_buildManifest.js" defer=""></script>
+var buildManifestPathRegex = regexp.MustCompile(`src="(/_next/static/[^"/]+/_buildManifest\.js)"`)
+
+// "buildId":"mXn2C3tHQpi_TGDh1yc0H"
+var buildIDRegex = regexp.MustCompile(`"buildId"\s*:\s*"([^"]+)"`)
+
+// "static/chunks/pages/_app-3c1a7b0e1d2f4a5b.js"
+var manifestChunkPathRegex = regexp.MustCompile(`"(static/chunks/[^"]+\.js)"`)
+
+// KeySource gives [KeyExtractor] implementations access to the website.
+// The homepage is fetched at most once, no matter the number of extractors.
+type KeySource struct {
+	client *Client
+
+	homepageOnce sync.Once
+	homepage     []byte
+	homepageErr  error
+}
+
+// NewKeySource returns a [KeySource] for the website of client.
+func NewKeySource(client *Client) *KeySource {
+	return &KeySource{client: client}
+}
+
+// Client returns the client used to access the website.
+func (s *KeySource) Client() *Client {
+	return s.client
+}
+
+// Homepage returns the source of the website's homepage.
+func (s *KeySource) Homepage(ctx context.Context) ([]byte, error) {
+	s.homepageOnce.Do(func() {
+		s.homepage, s.homepageErr = s.Fetch(ctx, "/")
+	})
+	return s.homepage, s.homepageErr
+}
+
+// Fetch returns the body of a resource. Paths starting with "/" are resolved
+// relative to the website.
+func (s *KeySource) Fetch(ctx context.Context, path string) ([]byte, error) {
+	u, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.client.UserAgent != "" {
+		req.Header.Set("User-Agent", s.client.UserAgent)
+	}
+
+	res, err := s.client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	return io.ReadAll(res.Body)
+}
+
+func (s *KeySource) resolve(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return path, nil
+	}
+
+	u, err := s.client.webURL(path)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+// KeyExtractor is a strategy for identifying the API key used by the website.
+type KeyExtractor interface {
+	// Name returns a short name of the strategy, such as "chunks".
+	Name() string
+	// ExtractAPIKey returns the API key, or an error if it could not be found.
+	ExtractAPIKey(ctx context.Context, source *KeySource) (string, error)
+}
+
+type keyExtractorFunc struct {
+	name string
+	fn   func(ctx context.Context, source *KeySource) (string, error)
+}
+
+// NewKeyExtractor returns a [KeyExtractor] with the given name, calling fn to
+// extract the API key.
+func NewKeyExtractor(name string, fn func(ctx context.Context, source *KeySource) (string, error)) KeyExtractor {
+	return &keyExtractorFunc{name: name, fn: fn}
+}
+
+// Name implements [KeyExtractor].
+func (e *keyExtractorFunc) Name() string {
+	return e.name
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *keyExtractorFunc) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	return e.fn(ctx, source)
+}
+
+// DefaultKeyExtractors returns the strategies used by [Client.GetAPIKey]
+// unless otherwise specified by [Client.KeyExtractors], in order:
+//
+//   - The SYSTEMBOLAGET_API_KEY environment variable.
+//   - Inline scripts and data of the homepage, such as __NEXT_DATA__.
+//   - Script chunks referenced by the homepage.
+//   - Script chunks referenced by the Next.js build manifest.
+func DefaultKeyExtractors() []KeyExtractor {
+	return []KeyExtractor{
+		&EnvKeyExtractor{Variable: "SYSTEMBOLAGET_API_KEY"},
+		&InlineKeyExtractor{},
+		&ChunkKeyExtractor{},
+		&BuildManifestKeyExtractor{},
+	}
+}
+
+// EnvKeyExtractor reads the API key from an environment variable.
+type EnvKeyExtractor struct {
+	Variable string
+}
+
+// Name implements [KeyExtractor].
+func (e *EnvKeyExtractor) Name() string {
+	return "env"
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *EnvKeyExtractor) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	apiKey := strings.TrimSpace(os.Getenv(e.Variable))
+	if apiKey == "" {
+		return "", fmt.Errorf("environment variable %s is not set", e.Variable)
+	}
+	return apiKey, nil
+}
+
+// FileKeyExtractor reads the API key from a file.
+type FileKeyExtractor struct {
+	Path string
+}
+
+// Name implements [KeyExtractor].
+func (e *FileKeyExtractor) Name() string {
+	return "file"
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *FileKeyExtractor) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	data, err := os.ReadFile(e.Path)
+	if err != nil {
+		return "", err
+	}
+
+	apiKey := strings.TrimSpace(string(data))
+	if apiKey == "" {
+		return "", fmt.Errorf("file %s is empty", e.Path)
+	}
+	return apiKey, nil
+}
+
+// InlineKeyExtractor looks for the API key in the homepage itself, such as in
+// the __NEXT_DATA__ block or inline environment scripts.
+type InlineKeyExtractor struct{}
+
+// Name implements [KeyExtractor].
+func (e *InlineKeyExtractor) Name() string {
+	return "inline"
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *InlineKeyExtractor) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	homepage, err := source.Homepage(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	match := apiKeyRegex.FindSubmatch(homepage)
+	if match == nil {
+		return "", fmt.Errorf("api token not found in homepage")
+	}
+
+	return string(match[1]), nil
+}
+
+// ChunkKeyExtractor looks for the API key in the script chunks referenced by
+// the homepage.
+type ChunkKeyExtractor struct {
+	// Concurrency is the maximum number of chunks fetched concurrently.
+	// Defaults to [DefaultChunkConcurrency].
+	Concurrency int
+}
+
+// Name implements [KeyExtractor].
+func (e *ChunkKeyExtractor) Name() string {
+	return "chunks"
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *ChunkKeyExtractor) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	homepage, err := source.Homepage(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	matches := chunkPathRegex.FindAllSubmatch(homepage, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("unable to identify script chunks")
+	}
+
+	paths := make([]string, 0, len(matches))
+	for _, match := range matches {
+		paths = append(paths, string(match[1]))
+	}
+
+	return scanChunks(ctx, source, paths, e.Concurrency)
+}
+
+// BuildManifestKeyExtractor looks for the API key in the script chunks listed
+// in the Next.js build manifest.
+type BuildManifestKeyExtractor struct {
+	// Concurrency is the maximum number of chunks fetched concurrently.
+	// Defaults to [DefaultChunkConcurrency].
+	Concurrency int
+}
+
+// Name implements [KeyExtractor].
+func (e *BuildManifestKeyExtractor) Name() string {
+	return "build-manifest"
+}
+
+// ExtractAPIKey implements [KeyExtractor].
+func (e *BuildManifestKeyExtractor) ExtractAPIKey(ctx context.Context, source *KeySource) (string, error) {
+	homepage, err := source.Homepage(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var manifestPath string
+	if match := buildManifestPathRegex.FindSubmatch(homepage); match != nil {
+		manifestPath = string(match[1])
+	} else if match := buildIDRegex.FindSubmatch(homepage); match != nil {
+		manifestPath = "/_next/static/" + string(match[1]) + "/_buildManifest.js"
+	} else {
+		return "", fmt.Errorf("unable to identify build manifest")
+	}
+
+	manifest, err := source.Fetch(ctx, manifestPath)
+	if err != nil {
+		return "", err
+	}
+
+	matches := manifestChunkPathRegex.FindAllSubmatch(manifest, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("unable to identify script chunks in build manifest")
+	}
+
+	paths := make([]string, 0, len(matches))
+	for _, match := range matches {
+		paths = append(paths, "/_next/"+string(match[1]))
+	}
+
+	return scanChunks(ctx, source, paths, e.Concurrency)
+}
+
+// DefaultChunkConcurrency is the default number of script chunks fetched
+// concurrently when looking for the API key.
+const DefaultChunkConcurrency = 4
+
+// ChunkFailure describes why a script chunk did not yield an API key.
+type ChunkFailure struct {
+	Path string
+	Err  error
+}
+
+// ChunkScanError is returned when no script chunk contains the API key.
+type ChunkScanError struct {
+	// Failures holds the reason each tried chunk failed, in the order the
+	// chunks were tried.
+	Failures []ChunkFailure
+}
+
+// Error implements error.
+func (e *ChunkScanError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "unable to identify API token in any of %d script chunks", len(e.Failures))
+	for _, failure := range e.Failures {
+		fmt.Fprintf(&builder, "\n%s: %s", failure.Path, failure.Err)
+	}
+	return builder.String()
+}
+
+// Unwrap returns the errors of each failed chunk.
+func (e *ChunkScanError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// errAPIKeyNotInChunk is returned for chunks that don't contain the API key.
+var errAPIKeyNotInChunk = errors.New("api token not found in chunk")
+
+// chunkPriority returns the priority of a script chunk based on its path, where
+// lower values are tried first. Chunks holding application code, such as
+// _app, are more likely to include the environment than framework chunks.
+func chunkPriority(path string) int {
+	name := path[strings.LastIndex(path, "/")+1:]
+	switch {
+	case strings.Contains(path, "_app"), strings.Contains(name, "main-app"), strings.Contains(path, "/app/layout"):
+		return 0
+	case strings.Contains(path, "/pages/"), strings.Contains(path, "/app/"):
+		return 1
+	case strings.HasPrefix(name, "webpack"), strings.HasPrefix(name, "polyfills"), strings.HasPrefix(name, "framework"):
+		return 3
+	default:
+		return 2
+	}
+}
+
+// scanChunks returns the first API key found in the script chunks at paths.
+// Chunks are fetched concurrently by at most concurrency workers, in order of
+// [chunkPriority]. Remaining fetches are cancelled once a key is found.
+func scanChunks(ctx context.Context, source *KeySource, paths []string, concurrency int) (string, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultChunkConcurrency
+	}
+
+	unique := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		if _, ok := seen[path]; !ok {
+			seen[path] = struct{}{}
+			unique = append(unique, path)
+		}
+	}
+	paths = unique
+
+	slices.SortStableFunc(paths, func(a string, b string) int {
+		return chunkPriority(a) - chunkPriority(b)
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index  int
+		apiKey string
+		err    error
+	}
+
+	jobs := make(chan int)
+	results := make(chan result)
+
+	go func() {
+		defer close(jobs)
+		for i := range paths {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(paths)) {
+		wg.Go(func() {
+			for i := range jobs {
+				chunk, err := source.Fetch(ctx, paths[i])
+				if err != nil {
+					results <- result{index: i, err: err}
+					continue
+				}
+
+				match := apiKeyRegex.FindSubmatch(chunk)
+				if match == nil {
+					results <- result{index: i, err: errAPIKeyNotInChunk}
+					continue
+				}
+
+				results <- result{index: i, apiKey: string(match[1])}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	apiKey := ""
+	failures := make([]error, len(paths))
+	for result := range results {
+		if result.err != nil {
+			failures[result.index] = result.err
+		} else if apiKey == "" {
+			apiKey = result.apiKey
+			cancel()
+		}
+	}
+
+	if apiKey != "" {
+		return apiKey, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	scanErr := &ChunkScanError{}
+	for i, err := range failures {
+		if err != nil {
+			scanErr.Failures = append(scanErr.Failures, ChunkFailure{Path: paths[i], Err: err})
+		}
+	}
+
+	return "", scanErr
+}
+
+// KeyExtractionAttempt describes the outcome of a single [KeyExtractor].
+type KeyExtractionAttempt struct {
+	Extractor string
+	Err       error
+}
+
+// KeyExtractionReport describes how an API key was extracted.
+type KeyExtractionReport struct {
+	// Extractor is the name of the strategy that succeeded, if any.
+	Extractor string
+	// Attempts holds the outcome of each tried strategy, in order.
+	Attempts []KeyExtractionAttempt
+}
+
+// ExtractAPIKey tries each of the client's key extractors in order and returns
+// the first identified API key, along with a report of the tried strategies.
+func (c *Client) ExtractAPIKey(ctx context.Context) (string, *KeyExtractionReport, error) {
+	extractors := c.KeyExtractors
+	if extractors == nil {
+		extractors = DefaultKeyExtractors()
+	}
+
+	source := NewKeySource(c)
+	report := &KeyExtractionReport{}
+	errs := make([]error, 0, len(extractors))
+	for _, extractor := range extractors {
+		apiKey, err := extractor.ExtractAPIKey(ctx, source)
+		if err == nil && apiKey == "" {
+			err = fmt.Errorf("empty api key")
+		}
+
+		report.Attempts = append(report.Attempts, KeyExtractionAttempt{
+			Extractor: extractor.Name(),
+			Err:       err,
+		})
+
+		if err == nil {
+			report.Extractor = extractor.Name()
+			return apiKey, report, nil
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", report, ctxErr
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", extractor.Name(), err))
+	}
+
+	return "", report, fmt.Errorf("unable to identify API key: %w", errors.Join(errs...))
+}
@@ -0,0 +1,108 @@
+package systembolaget
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRegex(t *testing.T) {
+	testCases := []string{
+		`env:{NEXT_PUBLIC_API_KEY_APIM:"abc123"}`,
+		`{"NEXT_PUBLIC_API_KEY_APIM":"abc123"}`,
+		`self.__next_f.push([1,"{\"NEXT_PUBLIC_API_KEY_APIM\":\"abc123\"}"])`,
+	}
+
+	for _, testCase := range testCases {
+		match := apiKeyRegex.FindStringSubmatch(testCase)
+		if assert.NotNil(t, match, testCase) {
+			assert.Equal(t, "abc123", match[1])
+		}
+	}
+}
+
+func TestClientExtractAPIKey(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<script id="__NEXT_DATA__" type="application/json">{"buildId":"build"}</script>`))
+	})
+	mux.HandleFunc("/_next/static/build/_buildManifest.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`self.__BUILD_MANIFEST={"/":["static/chunks/pages/index.js"]}`))
+	})
+	mux.HandleFunc("/_next/static/chunks/pages/index.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`env:{NEXT_PUBLIC_API_KEY_APIM:"manifest-key"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &Client{
+		Client:     server.Client(),
+		WebBaseURL: server.URL,
+		KeyExtractors: []KeyExtractor{
+			&InlineKeyExtractor{},
+			&ChunkKeyExtractor{},
+			&BuildManifestKeyExtractor{},
+		},
+	}
+
+	apiKey, report, err := client.ExtractAPIKey(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "manifest-key", apiKey)
+	assert.Equal(t, "build-manifest", report.Extractor)
+	require.Len(t, report.Attempts, 3)
+	assert.Error(t, report.Attempts[0].Err)
+	assert.Error(t, report.Attempts[1].Err)
+	assert.NoError(t, report.Attempts[2].Err)
+
+	client.KeyExtractors = []KeyExtractor{
+		NewKeyExtractor("custom", func(ctx context.Context, source *KeySource) (string, error) {
+			return "", errors.New("custom failure")
+		}),
+	}
+
+	_, report, err = client.ExtractAPIKey(context.TODO())
+	assert.ErrorContains(t, err, "custom: custom failure")
+	assert.Equal(t, "", report.Extractor)
+}
+
+func TestChunkKeyExtractor(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+			<script src="/_next/static/chunks/webpack.js" defer=""></script>
+			<script src="/_next/static/chunks/missing.js" defer=""></script>
+			<script src="/_next/static/chunks/other.js" defer=""></script>
+			<script src="/_next/static/chunks/other.js" defer=""></script>
+		`))
+	})
+	mux.HandleFunc("/_next/static/chunks/{chunk}", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.PathValue("chunk") == "missing.js" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`console.log("hello")`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &Client{Client: server.Client(), WebBaseURL: server.URL}
+
+	_, err := (&ChunkKeyExtractor{Concurrency: 2}).ExtractAPIKey(context.TODO(), NewKeySource(client))
+	var scanErr *ChunkScanError
+	require.ErrorAs(t, err, &scanErr)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(3), requests.Load())
+	require.Len(t, scanErr.Failures, 3)
+	// Framework chunks are tried last
+	assert.Equal(t, "/_next/static/chunks/missing.js", scanErr.Failures[0].Path)
+	assert.Equal(t, "/_next/static/chunks/other.js", scanErr.Failures[1].Path)
+	assert.Equal(t, "/_next/static/chunks/webpack.js", scanErr.Failures[2].Path)
+}
@@ -0,0 +1,181 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultKeyTTL is the default duration for which a stored API key is reused.
+const DefaultKeyTTL = 24 * time.Hour
+
+// StoredAPIKey is an API key kept in a [KeyStore].
+type StoredAPIKey struct {
+	APIKey      string    `json:"apiKey"`
+	RetrievedAt time.Time `json:"retrievedAt"`
+}
+
+// KeyStore persists API keys so that they may be reused instead of being
+// retrieved from the website every time.
+type KeyStore interface {
+	// LoadAPIKey returns the stored API key, or nil if there is none.
+	LoadAPIKey(ctx context.Context) (*StoredAPIKey, error)
+	// StoreAPIKey stores an API key, replacing any previous key.
+	StoreAPIKey(ctx context.Context, key *StoredAPIKey) error
+}
+
+var _ KeyStore = (*FileKeyStore)(nil)
+
+// FileKeyStore is a [KeyStore] keeping the API key in a JSON file.
+type FileKeyStore struct {
+	Path string
+}
+
+// NewFileKeyStore returns a [FileKeyStore] using the file at path.
+func NewFileKeyStore(path string) *FileKeyStore {
+	return &FileKeyStore{Path: path}
+}
+
+// DefaultKeyStorePath returns the path of the API key cache in the user's cache
+// directory.
+func DefaultKeyStorePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "systembolaget", "api-key.json"), nil
+}
+
+// LoadAPIKey implements [KeyStore].
+func (s *FileKeyStore) LoadAPIKey(ctx context.Context) (*StoredAPIKey, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var key StoredAPIKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+
+	if key.APIKey == "" {
+		return nil, nil
+	}
+
+	return &key, nil
+}
+
+// StoreAPIKey implements [KeyStore].
+// The file is replaced atomically and is only readable by the current user.
+func (s *FileKeyStore) StoreAPIKey(ctx context.Context, key *StoredAPIKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(s.Path), ".api-key-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.Path)
+}
+
+// ValidateAPIKey performs a cheap request to check whether or not the client's
+// API key is accepted. The key is not refreshed if rejected.
+func (c *AuthenticatedClient) ValidateAPIKey(ctx context.Context) error {
+	probe := c
+	if c.Parent != nil {
+		// Use a client without a parent so that the key is not refreshed
+		probe = &AuthenticatedClient{
+			APIKey:      c.CurrentAPIKey(),
+			Client:      c.Client,
+			UserAgent:   c.UserAgent,
+			APIBaseURL:  c.APIBaseURL,
+			WebBaseURL:  c.WebBaseURL,
+			RetryPolicy: c.RetryPolicy,
+			RateLimiter: c.RateLimiter,
+		}
+	}
+
+	_, err := probe.Search(ctx, &SearchOptions{PageSize: 1})
+	return err
+}
+
+// loadAPIKey returns a stored API key if it has not expired and it's accepted
+// by the server. Returns false if there is no usable stored key. Errors other
+// than the key being rejected, such as network errors, are returned as is.
+func (c *Client) loadAPIKey(ctx context.Context) (string, bool, error) {
+	key, err := c.KeyStore.LoadAPIKey(ctx)
+	if err != nil {
+		slog.Warn("Failed to load stored API key", slog.Any("error", err))
+		return "", false, nil
+	} else if key == nil {
+		return "", false, nil
+	}
+
+	ttl := c.KeyTTL
+	if ttl <= 0 {
+		ttl = DefaultKeyTTL
+	}
+
+	if time.Since(key.RetrievedAt) > ttl {
+		slog.Debug("Stored API key has expired", slog.Time("retrievedAt", key.RetrievedAt))
+		return "", false, nil
+	}
+
+	probe := &AuthenticatedClient{
+		APIKey:      key.APIKey,
+		Client:      c.Client,
+		UserAgent:   c.UserAgent,
+		APIBaseURL:  c.APIBaseURL,
+		WebBaseURL:  c.WebBaseURL,
+		RetryPolicy: c.RetryPolicy,
+		RateLimiter: c.RateLimiter,
+	}
+	if err := probe.ValidateAPIKey(ctx); errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) {
+		slog.Debug("Stored API key was rejected", slog.Any("error", err))
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	return key.APIKey, true, nil
+}
+
+// storeAPIKey stores a retrieved API key, if the client has a key store.
+func (c *Client) storeAPIKey(ctx context.Context, apiKey string) {
+	if c.KeyStore == nil {
+		return
+	}
+
+	err := c.KeyStore.StoreAPIKey(ctx, &StoredAPIKey{
+		APIKey:      apiKey,
+		RetrievedAt: time.Now(),
+	})
+	if err != nil {
+		slog.Warn("Failed to store API key", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,99 @@
+package systembolaget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	store := NewFileKeyStore(filepath.Join(t.TempDir(), "cache", "api-key.json"))
+
+	key, err := store.LoadAPIKey(context.TODO())
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	expected := &StoredAPIKey{APIKey: "test-key", RetrievedAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, store.StoreAPIKey(context.TODO(), expected))
+
+	key, err = store.LoadAPIKey(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, expected, key)
+}
+
+func TestClientKeyStore(t *testing.T) {
+	var scrapes atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		scrapes.Add(1)
+		w.Write([]byte(`<script src="/_next/static/chunks/app.js" defer=""></script>`))
+	})
+	mux.HandleFunc("/_next/static/chunks/app.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`env:{NEXT_PUBLIC_API_KEY_APIM:"new-key"}`))
+	})
+	mux.HandleFunc("/sb-api-ecommerce/v1/productsearch/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Ocp-Apim-Subscription-Key") {
+		case "rejected-key":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case "unavailable-key":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewFileKeyStore(filepath.Join(t.TempDir(), "api-key.json"))
+	client := &Client{
+		Client:     server.Client(),
+		APIBaseURL: server.URL,
+		WebBaseURL: server.URL,
+		KeyStore:   store,
+	}
+
+	// Valid stored keys are reused
+	require.NoError(t, store.StoreAPIKey(context.TODO(), &StoredAPIKey{APIKey: "stored-key", RetrievedAt: time.Now()}))
+	authenticatedClient, err := client.GetAuthenticatedClient(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "stored-key", authenticatedClient.APIKey)
+	assert.Equal(t, int32(0), scrapes.Load())
+
+	// Expired keys are replaced
+	require.NoError(t, store.StoreAPIKey(context.TODO(), &StoredAPIKey{APIKey: "stored-key", RetrievedAt: time.Now().Add(-2 * DefaultKeyTTL)}))
+	authenticatedClient, err = client.GetAuthenticatedClient(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "new-key", authenticatedClient.APIKey)
+	assert.Equal(t, int32(1), scrapes.Load())
+
+	// Rejected keys are replaced
+	require.NoError(t, store.StoreAPIKey(context.TODO(), &StoredAPIKey{APIKey: "rejected-key", RetrievedAt: time.Now()}))
+	authenticatedClient, err = client.GetAuthenticatedClient(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "new-key", authenticatedClient.APIKey)
+	assert.Equal(t, int32(2), scrapes.Load())
+
+	key, err := store.LoadAPIKey(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "new-key", key.APIKey)
+
+	// Keys that can't be validated are kept and the error is returned
+	require.NoError(t, store.StoreAPIKey(context.TODO(), &StoredAPIKey{APIKey: "unavailable-key", RetrievedAt: time.Now()}))
+	_, err = client.GetAuthenticatedClient(context.TODO())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int32(2), scrapes.Load())
+
+	key, err = store.LoadAPIKey(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "unavailable-key", key.APIKey)
+}
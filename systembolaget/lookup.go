@@ -0,0 +1,168 @@
+package systembolaget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxLookupPages is the maximum number of pages searched when looking up a
+// single product.
+const maxLookupPages = 5
+
+// ErrAmbiguous is returned when a lookup matches more than one product.
+var ErrAmbiguous = errors.New("ambiguous")
+
+// ProductNotFoundError is returned when a product lookup yields no exact
+// match. It matches [ErrNotFound] using [errors.Is].
+type ProductNotFoundError struct {
+	// Field is the field that was looked up, such as "productId".
+	Field string
+	Value string
+}
+
+// Error implements error.
+func (e *ProductNotFoundError) Error() string {
+	return fmt.Sprintf("product not found: %s %s", e.Field, e.Value)
+}
+
+// Is implements errors.Is.
+func (e *ProductNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// GetProduct returns the product with the given product id, such as
+// "507849". Returns a [*ProductNotFoundError] if there is no such product.
+func (c *AuthenticatedClient) GetProduct(ctx context.Context, id string) (Product, error) {
+	score := func(p Product) int {
+		if v, _ := p.ID(); v == id {
+			return 1
+		}
+		return 0
+	}
+
+	return c.lookupProduct(ctx, "productId", id, 1, score, FilterByParameter("productId", id))
+}
+
+// GetProductByNumber returns the product with the given article number, such
+// as "125303" or the short "1253". Full article numbers take precedence over
+// short ones. Returns a [*ProductNotFoundError] if there is no such product.
+func (c *AuthenticatedClient) GetProductByNumber(ctx context.Context, number string) (Product, error) {
+	score := func(p Product) int {
+		if v, _ := p.Number(); v == number {
+			return 2
+		}
+		if v, _ := p.getNonEmptyString("productNumberShort"); v == number {
+			return 1
+		}
+		return 0
+	}
+
+	return c.lookupProduct(ctx, "productNumber", number, 2, score,
+		FilterByParameter("productNumber", number),
+		FilterByParameter("productNumberShort", number),
+	)
+}
+
+// productLookup keeps track of the best candidate of a product lookup.
+type productLookup struct {
+	maxScore  int
+	score     func(Product) int
+	best      Product
+	bestScore int
+	ambiguous bool
+}
+
+// isDone returns whether or not the best possible match has been found.
+func (l *productLookup) isDone() bool {
+	return l.bestScore == l.maxScore && !l.ambiguous
+}
+
+// lookupProduct searches for value and returns the candidate with the highest
+// non-zero score. The search is widened page by page until an unambiguous
+// match with the maximum score is found or there are no more results.
+//
+// Text searches don't necessarily match article numbers or ids, so if the
+// search yields no candidate, a search using each of the fallback filters is
+// made in turn until one yields a candidate. As the fallback filters are not
+// documented by the API, only their first page is searched, and no further
+// fallbacks are tried once one is found to be ignored.
+func (c *AuthenticatedClient) lookupProduct(ctx context.Context, field string, value string, maxScore int, score func(Product) int, fallbacks ...SearchFilter) (Product, error) {
+	if value == "" {
+		return nil, &ProductNotFoundError{Field: field, Value: value}
+	}
+
+	lookup := &productLookup{maxScore: maxScore, score: score}
+	if _, err := c.searchProductLookup(ctx, lookup, FilterByQuery(value), maxLookupPages); err != nil {
+		return nil, err
+	}
+
+	for _, filter := range fallbacks {
+		if lookup.best != nil {
+			break
+		}
+
+		ignored, err := c.searchProductLookup(ctx, lookup, filter, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if ignored {
+			break
+		}
+	}
+
+	if lookup.best == nil {
+		return nil, &ProductNotFoundError{Field: field, Value: value}
+	}
+
+	if lookup.ambiguous {
+		return nil, fmt.Errorf("%w: multiple products match %s %s", ErrAmbiguous, field, value)
+	}
+
+	return lookup.best, nil
+}
+
+// searchProductLookup searches up to maxPages pages using filter, scoring the
+// candidates of each page. Returns whether or not the filter was ignored by
+// the API, that is if it matched the full assortment.
+func (c *AuthenticatedClient) searchProductLookup(ctx context.Context, lookup *productLookup, filter SearchFilter, maxPages int) (bool, error) {
+	ignored := false
+	options := &SearchOptions{}
+	for page := 1; page <= maxPages; page++ {
+		options.Page = page
+		result, err := c.Search(ctx, options, filter)
+		if err != nil {
+			return false, err
+		}
+
+		if page == 1 {
+			count := result.Metadata.DocumentCount
+			ignored = count > 0 && count == result.Metadata.FullAssortmentDocumentCount
+		}
+
+		for _, product := range result.Products {
+			s := lookup.score(product)
+			if s == 0 {
+				continue
+			}
+
+			id, _ := product.ID()
+			bestID, _ := lookup.best.ID()
+			switch {
+			case s > lookup.bestScore:
+				lookup.best, lookup.bestScore, lookup.ambiguous = product, s, false
+			case s == lookup.bestScore && id != bestID:
+				lookup.ambiguous = true
+			}
+		}
+
+		// Stop widening the search once the best possible match has been found,
+		// or when there are no more results
+		if lookup.isDone() || result.Metadata.NextPage <= page {
+			break
+		}
+	}
+
+	return ignored, nil
+}
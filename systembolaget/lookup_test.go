@@ -0,0 +1,129 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLookupServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := [][]Product{
+		{
+			{"productId": "5078490", "productNumber": "5078490", "productNumberShort": "50784"},
+			{"productId": "1", "productNumber": "150784", "productNumberShort": "507849"},
+		},
+		{
+			{"productId": "507849", "productNumber": "157849", "productNumberShort": "1578"},
+			{"productId": "2", "productNumber": "507849", "productNumberShort": "5078"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var result SearchResult
+		result.Metadata.NextPage = -1
+		if page < len(pages) {
+			result.Metadata.NextPage = page + 1
+		}
+		if page <= len(pages) {
+			result.Products = pages[page-1]
+		}
+		json.NewEncoder(w).Encode(&result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthenticatedClientGetProduct(t *testing.T) {
+	server := newLookupServer(t)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	product, err := client.GetProduct(context.TODO(), "507849")
+	require.NoError(t, err)
+	assert.Equal(t, "157849", product["productNumber"])
+
+	_, err = client.GetProduct(context.TODO(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
+	var notFoundErr *ProductNotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestAuthenticatedClientGetProductByNumber(t *testing.T) {
+	server := newLookupServer(t)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	// The full article number on the second page takes precedence over the
+	// short number on the first page
+	product, err := client.GetProductByNumber(context.TODO(), "507849")
+	require.NoError(t, err)
+	assert.Equal(t, "2", product["productId"])
+
+	product, err = client.GetProductByNumber(context.TODO(), "1578")
+	require.NoError(t, err)
+	assert.Equal(t, "507849", product["productId"])
+}
+
+func TestAuthenticatedClientGetProductByNumberFallback(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+
+		var result SearchResult
+		result.Metadata.NextPage = -1
+		// The text search doesn't match the short article number
+		if r.URL.Query().Get("productNumberShort") == "1253" {
+			result.Products = []Product{{"productId": "3", "productNumber": "125303", "productNumberShort": "1253"}}
+		}
+		json.NewEncoder(w).Encode(&result)
+	}))
+	t.Cleanup(server.Close)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	product, err := client.GetProductByNumber(context.TODO(), "1253")
+	require.NoError(t, err)
+	assert.Equal(t, "3", product["productId"])
+	require.Len(t, queries, 3)
+	assert.Contains(t, queries[0], "textQuery=1253")
+	assert.Contains(t, queries[1], "productNumber=1253")
+	assert.Contains(t, queries[2], "productNumberShort=1253")
+
+	queries = nil
+	_, err = client.GetProductByNumber(context.TODO(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, queries, 3)
+}
+
+func TestAuthenticatedClientGetProductByNumberIgnoredFallback(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		// Unknown parameters are ignored, yielding the full assortment
+		var result SearchResult
+		result.Metadata.FullAssortmentDocumentCount = 1000
+		result.Metadata.NextPage = -1
+		if r.URL.Query().Get("textQuery") == "" {
+			result.Metadata.DocumentCount = 1000
+			result.Metadata.NextPage = 2
+			result.Products = []Product{{"productId": "1", "productNumber": "100101"}}
+		}
+		json.NewEncoder(w).Encode(&result)
+	}))
+	t.Cleanup(server.Close)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	_, err := client.GetProductByNumber(context.TODO(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// The text search and a single page of the first fallback
+	assert.EqualValues(t, 2, requests.Load())
+}
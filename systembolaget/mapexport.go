@@ -0,0 +1,219 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// storeProperty is a named property of a store, as exported by
+// [WriteGeoJSON] and [WriteKML].
+type storeProperty struct {
+	name  string
+	value any
+}
+
+// storeProperties returns the properties of a store at the given time. If
+// stock is non-nil, the stock status is included.
+func storeProperties(store Store, stock *StockStatus, now time.Time) []storeProperty {
+	properties := []storeProperty{
+		{name: "siteId", value: store.SiteID},
+		{name: "name", value: storeName(store)},
+		{name: "alias", value: store.Alias},
+		{name: "streetAddress", value: store.StreetAddress},
+		{name: "city", value: store.City},
+		{name: "county", value: store.County},
+		{name: "isOpen", value: store.IsOpen},
+		{name: "isOpenNow", value: store.IsOpenAt(now)},
+		{name: "isTastingStore", value: store.IsTastingStore},
+		{name: "isAgent", value: store.IsAgent},
+		{name: "isSvanenCertified", value: store.IsSvanenCertified},
+		{name: "isBlocked", value: store.IsBlocked},
+		{name: "blockedText", value: store.BlockedText},
+	}
+
+	if closesAt, ok := store.ClosesAt(now); ok {
+		properties = append(properties, storeProperty{name: "closesAt", value: closesAt.Format(time.RFC3339)})
+	}
+
+	if nextOpening, ok := store.NextOpening(now); ok {
+		properties = append(properties, storeProperty{name: "nextOpening", value: nextOpening.Format(time.RFC3339)})
+	}
+
+	if stock != nil {
+		properties = append(properties,
+			storeProperty{name: "productId", value: stock.ProductID},
+			storeProperty{name: "stock", value: stock.Stock},
+			storeProperty{name: "shelf", value: stock.Shelf},
+			storeProperty{name: "isInStoreAssortment", value: stock.IsInStoreAssortment},
+		)
+	}
+
+	return properties
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	ID         string          `json:"id"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON writes the stores as a GeoJSON (RFC 7946) FeatureCollection of
+// points. Each feature's properties describe the store, such as its address,
+// whether it's open and whether it's a tasting store or an agent.
+//
+// If stock is non-nil, the stock status of each store, keyed by site id, is
+// included in the store's properties. Stores without a position are skipped.
+func WriteGeoJSON(w io.Writer, stores []Store, stock map[string]StockStatus) error {
+	now := time.Now()
+
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(stores)),
+	}
+
+	for _, store := range stores {
+		if store.Position == nil {
+			continue
+		}
+
+		feature := geoJSONFeature{
+			Type: "Feature",
+			ID:   store.SiteID,
+			Geometry: geoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{store.Position.Longitude, store.Position.Latitude},
+			},
+			Properties: make(map[string]any),
+		}
+
+		for _, property := range storeProperties(store, stockOf(stock, store.SiteID), now) {
+			feature.Properties[property.name] = property.value
+		}
+
+		collection.Features = append(collection.Features, feature)
+	}
+
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(&collection)
+}
+
+// stockOf returns the stock status of a store, if any.
+func stockOf(stock map[string]StockStatus, siteID string) *StockStatus {
+	status, ok := stock[siteID]
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+type kml struct {
+	XMLName  xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	ID           string          `xml:"id,attr"`
+	Name         string          `xml:"name"`
+	Address      string          `xml:"address,omitempty"`
+	Description  string          `xml:"description,omitempty"`
+	ExtendedData kmlExtendedData `xml:"ExtendedData"`
+	Point        kmlPoint        `xml:"Point"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes the stores as a KML document of placemarks. Each
+// placemark's extended data describes the store, see [WriteGeoJSON].
+//
+// If stock is non-nil, the stock status of each store, keyed by site id, is
+// included in the store's extended data and description. Stores without a
+// position are skipped.
+func WriteKML(w io.Writer, stores []Store, stock map[string]StockStatus) error {
+	now := time.Now()
+
+	document := kml{
+		Document: kmlDocument{
+			Name:       "Systembolaget",
+			Placemarks: make([]kmlPlacemark, 0, len(stores)),
+		},
+	}
+
+	for _, store := range stores {
+		if store.Position == nil {
+			continue
+		}
+
+		placemark := kmlPlacemark{
+			// XML ids may not start with a digit
+			ID:      "store-" + store.SiteID,
+			Name:    storeName(store),
+			Address: storeLocation(store),
+			Point: kmlPoint{
+				Coordinates: strconv.FormatFloat(store.Position.Longitude, 'f', -1, 64) + "," + strconv.FormatFloat(store.Position.Latitude, 'f', -1, 64),
+			},
+		}
+
+		status := stockOf(stock, store.SiteID)
+		switch {
+		case store.IsBlocked && store.BlockedText != "":
+			placemark.Description = store.BlockedText
+		case status != nil && status.Shelf != "":
+			placemark.Description = fmt.Sprintf("%d in stock (%s)", status.Stock, status.Shelf)
+		case status != nil:
+			placemark.Description = fmt.Sprintf("%d in stock", status.Stock)
+		}
+
+		for _, property := range storeProperties(store, status, now) {
+			placemark.ExtendedData.Data = append(placemark.ExtendedData.Data, kmlData{
+				Name:  property.name,
+				Value: fmt.Sprint(property.value),
+			})
+		}
+
+		document.Document.Placemarks = append(document.Document.Placemarks, placemark)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,86 @@
+package systembolaget
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mapExportStores = []Store{
+	{SiteID: "0102", DisplayName: "Fältöversten", StreetAddress: "Karlaplan 13", City: "STOCKHOLM", County: "Stockholms län", IsOpen: true, Position: &StorePosition{Latitude: 59.3382, Longitude: 18.0854}},
+	{SiteID: "9001", DisplayName: "Ombud Öckerö", IsAgent: true, IsBlocked: true, BlockedText: "Tillfälligt stängt", Position: &StorePosition{Latitude: 57.7092, Longitude: 11.6503}},
+	{SiteID: "9002", DisplayName: "Okänd"},
+}
+
+var mapExportStock = map[string]StockStatus{
+	"0102": {ProductID: "507849", StoreID: "0102", Stock: 12, Shelf: "Öl 4", IsInStoreAssortment: true},
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, WriteGeoJSON(&buffer, mapExportStores, mapExportStock))
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Type     string `json:"type"`
+			ID       string `json:"id"`
+			Geometry struct {
+				Type        string    `json:"type"`
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &collection))
+
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.Len(t, collection.Features, 2)
+
+	feature := collection.Features[0]
+	assert.Equal(t, "Feature", feature.Type)
+	assert.Equal(t, "0102", feature.ID)
+	assert.Equal(t, "Point", feature.Geometry.Type)
+	assert.Equal(t, []float64{18.0854, 59.3382}, feature.Geometry.Coordinates)
+	assert.Equal(t, "Fältöversten", feature.Properties["name"])
+	assert.Equal(t, true, feature.Properties["isOpen"])
+	assert.Equal(t, false, feature.Properties["isOpenNow"])
+	assert.Equal(t, false, feature.Properties["isAgent"])
+	assert.EqualValues(t, 12, feature.Properties["stock"])
+	assert.Equal(t, "Öl 4", feature.Properties["shelf"])
+
+	feature = collection.Features[1]
+	assert.Equal(t, true, feature.Properties["isAgent"])
+	assert.Equal(t, true, feature.Properties["isBlocked"])
+	assert.Equal(t, "Tillfälligt stängt", feature.Properties["blockedText"])
+	assert.NotContains(t, feature.Properties, "stock")
+}
+
+func TestWriteKML(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, WriteKML(&buffer, mapExportStores, mapExportStock))
+
+	assert.Contains(t, buffer.String(), `<kml xmlns="http://www.opengis.net/kml/2.2">`)
+
+	var document kml
+	require.NoError(t, xml.Unmarshal(buffer.Bytes(), &document))
+	require.Len(t, document.Document.Placemarks, 2)
+
+	placemark := document.Document.Placemarks[0]
+	assert.Equal(t, "store-0102", placemark.ID)
+	assert.Equal(t, "Fältöversten", placemark.Name)
+	assert.Equal(t, "Karlaplan 13, Stockholm", placemark.Address)
+	assert.Equal(t, "12 in stock (Öl 4)", placemark.Description)
+	assert.Equal(t, "18.0854,59.3382", placemark.Point.Coordinates)
+	assert.Contains(t, placemark.ExtendedData.Data, kmlData{Name: "stock", Value: "12"})
+	assert.Contains(t, placemark.ExtendedData.Data, kmlData{Name: "isTastingStore", Value: "false"})
+
+	placemark = document.Document.Placemarks[1]
+	assert.Equal(t, "Tillfälligt stängt", placemark.Description)
+	assert.Contains(t, placemark.ExtendedData.Data, kmlData{Name: "isAgent", Value: "true"})
+	assert.NotContains(t, placemark.ExtendedData.Data, kmlData{Name: "stock", Value: "0"})
+}
@@ -0,0 +1,179 @@
+package systembolaget
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Stockholm is the Europe/Stockholm time zone, in which opening hours are
+// specified.
+//
+// The time zone is loaded from the system's time zone database. On systems
+// without one, a time zone following the current CET/CEST rules is used
+// instead, which is correct for dates since 1996. Programs may import
+// time/tzdata to embed the full database.
+var Stockholm = loadStockholm()
+
+// stockholmRule is the POSIX TZ rule of the Europe/Stockholm time zone.
+const stockholmRule = "CET-1CEST,M3.5.0,M10.5.0/3"
+
+func loadStockholm() *time.Location {
+	location, err := time.LoadLocation("Europe/Stockholm")
+	if err == nil {
+		return location
+	}
+
+	location, err = time.LoadLocationFromTZData("Europe/Stockholm", stockholmTZData())
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// stockholmTZData returns time zone data in the TZif format (RFC 8536)
+// without transitions, only following [stockholmRule].
+func stockholmTZData() []byte {
+	// The version 1 and version 2 data blocks are identical, as there are no
+	// transitions. They hold a single local time type and its designation
+	block := func(data []byte) []byte {
+		data = append(data, "TZif2"...)
+		data = append(data, make([]byte, 15)...)
+		// isutcnt, isstdcnt, leapcnt, timecnt, typecnt, charcnt
+		for _, count := range []uint32{0, 0, 0, 0, 1, 4} {
+			data = binary.BigEndian.AppendUint32(data, count)
+		}
+		data = binary.BigEndian.AppendUint32(data, 3600)
+		data = append(data, 0, 0)
+		return append(data, "CET\x00"...)
+	}
+
+	data := block(nil)
+	data = block(data)
+	return append(data, "\n"+stockholmRule+"\n"...)
+}
+
+// OpeningHours are the parsed opening hours of a store on a single day, see
+// [StoreOpeningHours.Parse].
+type OpeningHours struct {
+	// Date is the start of the day in the [Stockholm] time zone.
+	Date time.Time
+	// Opens is when the store opens. Zero if the store is closed all day.
+	Opens time.Time
+	// Closes is when the store closes. Zero if the store is closed all day.
+	Closes time.Time
+	// Reason describes why the opening hours deviate from the normal ones,
+	// such as "Kristi Himmelfärdsdag".
+	Reason string
+}
+
+// IsClosed returns whether or not the store is closed all day.
+func (h OpeningHours) IsClosed() bool {
+	return h.Opens.IsZero()
+}
+
+// IsSpecialDay returns whether or not the day has special opening hours, such
+// as on holidays. The API uses "-" as the reason for regular closed days, such
+// as Sundays, which are not considered special.
+func (h OpeningHours) IsSpecialDay() bool {
+	return h.Reason != "" && h.Reason != "-"
+}
+
+// IsOpenAt returns whether or not the store is open at t.
+func (h OpeningHours) IsOpenAt(t time.Time) bool {
+	return !h.IsClosed() && !t.Before(h.Opens) && t.Before(h.Closes)
+}
+
+// Parse returns the opening hours with dates and times in the [Stockholm]
+// time zone. Stores closing at or before they open, other than those closed
+// all day, are assumed to close after midnight.
+func (h StoreOpeningHours) Parse() (OpeningHours, error) {
+	date, err := time.ParseInLocation("2006-01-02T15:04:05", h.Date, Stockholm)
+	if err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	hours := OpeningHours{
+		Date:   date,
+		Reason: h.Reason,
+	}
+
+	opens, err := parseTimeOfDay(date, h.OpenFrom)
+	if err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid opening time: %w", err)
+	}
+
+	closes, err := parseTimeOfDay(date, h.OpenTo)
+	if err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid closing time: %w", err)
+	}
+
+	if opens.Equal(closes) {
+		return hours, nil
+	}
+
+	if closes.Before(opens) {
+		closes = closes.AddDate(0, 0, 1)
+	}
+
+	hours.Opens = opens
+	hours.Closes = closes
+	return hours, nil
+}
+
+// parseTimeOfDay returns the time of day, such as "10:00:00", on date.
+func parseTimeOfDay(date time.Time, value string) (time.Time, error) {
+	clock, err := time.Parse("15:04:05", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, Stockholm), nil
+}
+
+// ParsedOpeningHours returns the store's opening hours, see
+// [StoreOpeningHours.Parse]. Entries that fail to parse are skipped.
+func (s *Store) ParsedOpeningHours() []OpeningHours {
+	hours := make([]OpeningHours, 0, len(s.OpeningHours))
+	for _, h := range s.OpeningHours {
+		parsed, err := h.Parse()
+		if err == nil {
+			hours = append(hours, parsed)
+		}
+	}
+	return hours
+}
+
+// IsOpenAt returns whether or not the store is open at t, according to its
+// opening hours. Opening hours are only known for the coming weeks, stores
+// are considered closed outside of them.
+func (s *Store) IsOpenAt(t time.Time) bool {
+	_, ok := s.ClosesAt(t)
+	return ok
+}
+
+// ClosesAt returns when the store closes if it's open at t.
+func (s *Store) ClosesAt(t time.Time) (time.Time, bool) {
+	for _, h := range s.ParsedOpeningHours() {
+		if h.IsOpenAt(t) {
+			return h.Closes, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextOpening returns the next time the store opens after t. If the store is
+// open at t, the opening after the current one is returned.
+func (s *Store) NextOpening(t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, h := range s.ParsedOpeningHours() {
+		if h.IsClosed() || !h.Opens.After(t) {
+			continue
+		}
+
+		if next.IsZero() || h.Opens.Before(next) {
+			next = h.Opens
+		}
+	}
+	return next, !next.IsZero()
+}
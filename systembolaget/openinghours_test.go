@@ -0,0 +1,101 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	// The fallback time zone is compared to the embedded database
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreOpeningHoursParse(t *testing.T) {
+	hours, err := StoreOpeningHours{Date: "2024-05-02T00:00:00", OpenFrom: "10:00:00", OpenTo: "19:00:00"}.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, Stockholm), hours.Date)
+	assert.Equal(t, time.Date(2024, 5, 2, 10, 0, 0, 0, Stockholm), hours.Opens)
+	assert.Equal(t, time.Date(2024, 5, 2, 19, 0, 0, 0, Stockholm), hours.Closes)
+	assert.Equal(t, "2024-05-02T08:00:00Z", hours.Opens.UTC().Format(time.RFC3339))
+	assert.False(t, hours.IsClosed())
+	assert.False(t, hours.IsSpecialDay())
+
+	hours, err = StoreOpeningHours{Date: "2024-05-05T00:00:00", OpenFrom: "00:00:00", OpenTo: "00:00:00", Reason: "-"}.Parse()
+	require.NoError(t, err)
+	assert.True(t, hours.IsClosed())
+	assert.False(t, hours.IsSpecialDay())
+
+	hours, err = StoreOpeningHours{Date: "2024-05-09T00:00:00", OpenFrom: "00:00:00", OpenTo: "00:00:00", Reason: "Kristi Himmelfärdsdag"}.Parse()
+	require.NoError(t, err)
+	assert.True(t, hours.IsClosed())
+	assert.True(t, hours.IsSpecialDay())
+
+	hours, err = StoreOpeningHours{Date: "2024-05-10T00:00:00", OpenFrom: "20:00:00", OpenTo: "01:00:00"}.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 11, 1, 0, 0, 0, Stockholm), hours.Closes)
+
+	_, err = StoreOpeningHours{Date: "2024-05-10", OpenFrom: "10:00:00", OpenTo: "19:00:00"}.Parse()
+	assert.Error(t, err)
+
+	_, err = StoreOpeningHours{Date: "2024-05-10T00:00:00", OpenFrom: "10", OpenTo: "19:00:00"}.Parse()
+	assert.Error(t, err)
+}
+
+func TestStoreOpeningHours(t *testing.T) {
+	content, err := os.ReadFile("../samples/stores.json")
+	require.NoError(t, err)
+
+	var store Store
+	require.NoError(t, json.Unmarshal(content, &store))
+
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2024, 5, day, hour, minute, 0, 0, Stockholm)
+	}
+
+	assert.True(t, store.IsOpenAt(at(2, 10, 0)))
+	assert.True(t, store.IsOpenAt(at(2, 18, 59)))
+	assert.False(t, store.IsOpenAt(at(2, 19, 0)))
+	assert.False(t, store.IsOpenAt(at(2, 9, 59)))
+	// The same instant in another time zone
+	assert.True(t, store.IsOpenAt(at(2, 10, 0).UTC()))
+
+	closes, ok := store.ClosesAt(at(4, 12, 0))
+	require.True(t, ok)
+	assert.Equal(t, at(4, 15, 0), closes)
+
+	_, ok = store.ClosesAt(at(5, 12, 0))
+	assert.False(t, ok)
+
+	// Closed on Sunday the 5th, open again on Monday
+	next, ok := store.NextOpening(at(4, 16, 0))
+	require.True(t, ok)
+	assert.Equal(t, at(6, 10, 0), next)
+
+	// Closed on Kristi Himmelfärdsdag
+	next, ok = store.NextOpening(at(8, 12, 0))
+	require.True(t, ok)
+	assert.Equal(t, at(10, 10, 0), next)
+
+	_, ok = store.NextOpening(at(2, 10, 0).AddDate(1, 0, 0))
+	assert.False(t, ok)
+}
+
+func TestStockholmFallback(t *testing.T) {
+	expected, err := time.LoadLocation("Europe/Stockholm")
+	require.NoError(t, err)
+
+	fallback, err := time.LoadLocationFromTZData("Europe/Stockholm", stockholmTZData())
+	require.NoError(t, err)
+
+	// Compare every hour of two years, including four transitions
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for tm := start; tm.Year() < 2026; tm = tm.Add(time.Hour) {
+		name, offset := tm.In(expected).Zone()
+		fallbackName, fallbackOffset := tm.In(fallback).Zone()
+		require.Equal(t, offset, fallbackOffset, tm)
+		require.Equal(t, name, fallbackName, tm)
+	}
+}
@@ -0,0 +1,205 @@
+package systembolaget
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// MaxSearchPages is the maximum number of pages the API returns for a single
+// search.
+// SEE: https://github.com/AlexGustafsson/systembolaget-api/issues/9
+const MaxSearchPages = 333
+
+// IncompleteResultsError is returned by [AuthenticatedClient.AllProducts] when
+// the number of retrieved products does not match the number of products
+// reported by the API.
+type IncompleteResultsError struct {
+	Expected  int
+	Retrieved int
+}
+
+// Error implements error.
+func (e *IncompleteResultsError) Error() string {
+	return fmt.Sprintf("incomplete results: retrieved %d out of %d products", e.Retrieved, e.Expected)
+}
+
+// AllProducts returns an iterator over all products of a search, working
+// around the limit of [MaxSearchPages] pages per search.
+//
+// Searches with more results than can be paged through are split into
+// disjoint sub-searches by price ranges, based on the document count and price
+// range reported by the API. Products are deduplicated on their product id.
+// Once all sub-searches have completed, the number of products is compared to
+// the number reported by the API and an [*IncompleteResultsError] is yielded
+// if they differ.
+//
+// The requested page is ignored. Products are sorted as specified within each
+// sub-search, but not across sub-searches. See [AuthenticatedClient.Pages] for
+// how pages are fetched.
+func (c *AuthenticatedClient) AllProducts(ctx context.Context, options *SearchOptions, filters ...SearchFilter) iter.Seq2[Product, error] {
+	var opts SearchOptions
+	if options != nil {
+		opts = *options
+	}
+	opts.Page = 1
+	if opts.PageSize == 0 {
+		opts.PageSize = 30
+	}
+
+	return func(yield func(Product, error) bool) {
+		p := &partitioner{
+			client:  c,
+			options: opts,
+			filters: filters,
+			seen:    make(map[string]struct{}),
+			yield:   yield,
+		}
+
+		first, err := p.search(ctx, nil)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		expected := first.Metadata.FullAssortmentDocumentCount
+		minimum := int(math.Floor(float64(first.Metadata.PriceRange.Minimum)))
+		maximum := int(math.Ceil(float64(first.Metadata.PriceRange.Maximum)))
+		if !p.run(ctx, first, nil, minimum, maximum) {
+			return
+		}
+
+		if p.retrieved != expected {
+			yield(nil, &IncompleteResultsError{Expected: expected, Retrieved: p.retrieved})
+		}
+	}
+}
+
+// partitioner holds the state of a search split into sub-searches.
+type partitioner struct {
+	client  *AuthenticatedClient
+	options SearchOptions
+	filters []SearchFilter
+
+	// requests is the number of performed requests, used to delay all but the
+	// first request.
+	requests int
+	// retrieved is the number of unique products yielded.
+	retrieved int
+	seen      map[string]struct{}
+	yield     func(Product, error) bool
+}
+
+// maxResults is the number of results that can be paged through in a single
+// search.
+func (p *partitioner) maxResults() int {
+	return MaxSearchPages * p.options.PageSize
+}
+
+// search fetches the first page of a sub-search.
+func (p *partitioner) search(ctx context.Context, filter *SearchFilter) (*SearchResult, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	options := p.options
+	return p.client.Search(ctx, &options, p.withFilter(filter)...)
+}
+
+// wait waits for the page delay between requests.
+func (p *partitioner) wait(ctx context.Context) error {
+	p.requests++
+	if p.requests == 1 || p.options.PageDelay <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-time.After(p.options.PageDelay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *partitioner) withFilter(filter *SearchFilter) []SearchFilter {
+	if filter == nil {
+		return p.filters
+	}
+
+	filters := make([]SearchFilter, 0, len(p.filters)+1)
+	filters = append(filters, p.filters...)
+	return append(filters, *filter)
+}
+
+// run yields the products of a sub-search whose first page is first. If the
+// sub-search has more results than can be paged through and its price range
+// can be split, it's split into two overlapping sub-searches instead.
+// Returns false if the iteration should stop.
+func (p *partitioner) run(ctx context.Context, first *SearchResult, filter *SearchFilter, minimum int, maximum int) bool {
+	count := first.Metadata.FullAssortmentDocumentCount
+	if count > p.maxResults() && maximum-minimum > 1 {
+		middle := minimum + (maximum-minimum)/2
+		slog.Debug("Splitting search", slog.Int("count", count), slog.Int("minimumPrice", minimum), slog.Int("maximumPrice", maximum))
+
+		for _, bounds := range [][2]int{{minimum, middle}, {middle, maximum}} {
+			filter := FilterByPrice(bounds[0], bounds[1])
+			page, err := p.search(ctx, &filter)
+			if err != nil {
+				p.yield(nil, err)
+				return false
+			}
+
+			if !p.run(ctx, page, &filter, bounds[0], bounds[1]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if count > p.maxResults() {
+		slog.Warn("Unable to split search further, results will be incomplete", slog.Int("count", count), slog.Int("minimumPrice", minimum), slog.Int("maximumPrice", maximum))
+	}
+
+	page := first
+	current := 1
+	for {
+		for _, product := range page.Products {
+			id, ok := product.ID()
+			if ok {
+				if _, ok := p.seen[id]; ok {
+					continue
+				}
+				p.seen[id] = struct{}{}
+			}
+
+			p.retrieved++
+			if !p.yield(product, nil) {
+				return false
+			}
+		}
+
+		// NextPage is -1 if there are no more pages
+		if page.Metadata.NextPage <= current || len(page.Products) == 0 {
+			return true
+		}
+		current = page.Metadata.NextPage
+
+		if err := p.wait(ctx); err != nil {
+			p.yield(nil, err)
+			return false
+		}
+
+		options := p.options
+		options.Page = current
+		var err error
+		page, err = p.client.Search(ctx, &options, p.withFilter(filter)...)
+		if err != nil {
+			p.yield(nil, err)
+			return false
+		}
+	}
+}
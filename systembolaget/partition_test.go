@@ -0,0 +1,106 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAssortmentServer returns a server searching count products, mimicking the
+// API's price filter, paging and page limit.
+func newAssortmentServer(t *testing.T, count int, reportedCount int) *httptest.Server {
+	t.Helper()
+
+	products := make([]Product, 0, count)
+	for i := range count {
+		products = append(products, Product{
+			"productId": strconv.Itoa(i),
+			"price":     float64(10 + i%200),
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		minimum, maximum := 0.0, 1e9
+		if v := query.Get("price.min"); v != "" {
+			minimum, _ = strconv.ParseFloat(v, 64)
+			maximum, _ = strconv.ParseFloat(query.Get("price.max"), 64)
+		}
+
+		var result SearchResult
+		matches := make([]Product, 0)
+		for _, product := range products {
+			price, _ := product.Price()
+			if price >= minimum && price <= maximum {
+				matches = append(matches, product)
+				if len(matches) == 1 || float32(price) < result.Metadata.PriceRange.Minimum {
+					result.Metadata.PriceRange.Minimum = float32(price)
+				}
+				result.Metadata.PriceRange.Maximum = max(result.Metadata.PriceRange.Maximum, float32(price))
+			}
+		}
+
+		size, _ := strconv.Atoi(query.Get("size"))
+		page, _ := strconv.Atoi(query.Get("page"))
+		start := min((page-1)*size, len(matches))
+		end := min(start+size, len(matches))
+		if page > MaxSearchPages {
+			start, end = 0, 0
+		}
+
+		result.Products = matches[start:end]
+		result.Metadata.FullAssortmentDocumentCount = len(matches)
+		if query.Get("price.min") == "" && reportedCount > 0 {
+			result.Metadata.FullAssortmentDocumentCount = reportedCount
+		}
+		result.Metadata.NextPage = -1
+		if end < len(matches) && page < MaxSearchPages {
+			result.Metadata.NextPage = page + 1
+		}
+
+		json.NewEncoder(w).Encode(&result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthenticatedClientAllProducts(t *testing.T) {
+	count := MaxSearchPages*30 + 2000
+	server := newAssortmentServer(t, count, 0)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	seen := make(map[string]struct{})
+	for product, err := range client.AllProducts(context.TODO(), nil) {
+		require.NoError(t, err)
+		id, _ := product.ID()
+		seen[id] = struct{}{}
+	}
+
+	assert.Len(t, seen, count)
+}
+
+func TestAuthenticatedClientAllProductsIncomplete(t *testing.T) {
+	server := newAssortmentServer(t, 100, 101)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	var lastErr error
+	retrieved := 0
+	for _, err := range client.AllProducts(context.TODO(), nil) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		retrieved++
+	}
+
+	assert.Equal(t, 100, retrieved)
+	var incompleteErr *IncompleteResultsError
+	require.ErrorAs(t, lastErr, &incompleteErr)
+	assert.Equal(t, 101, incompleteErr.Expected)
+}
@@ -0,0 +1,182 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"maps"
+	"strconv"
+	"time"
+)
+
+// Date is a date or timestamp returned by the API, such as
+// "2014-06-02T00:00:00". The API does not specify a time zone.
+type Date struct {
+	time.Time
+}
+
+// dateLayouts are the layouts accepted when parsing a [Date], in order.
+var dateLayouts = []string{
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if value == "" {
+		*d = Date{}
+		return nil
+	}
+
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, value)
+		if err == nil {
+			d.Time = t
+			return nil
+		}
+	}
+
+	return err
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.Format("2006-01-02T15:04:05"))
+}
+
+// TasteClock is a taste clock value, such as "TasteClockBody" = 6.
+type TasteClock struct {
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+}
+
+// ProductDetailsImage is an image of a product as returned by the API.
+// See [Product.Images] for usable URLs.
+type ProductDetailsImage struct {
+	ImageURL string `json:"imageUrl"`
+	FileType string `json:"fileType"`
+}
+
+// ProductDetails is a typed view of a [Product], see [Product.Decode].
+// Fields missing in the product are left empty.
+type ProductDetails struct {
+	ProductID          string `json:"productId"`
+	ProductNumber      string `json:"productNumber"`
+	ProductNumberShort string `json:"productNumberShort"`
+	ProductNameBold    string `json:"productNameBold"`
+	ProductNameThin    string `json:"productNameThin"`
+
+	ProducerName string `json:"producerName"`
+	SupplierName string `json:"supplierName"`
+
+	Category            string `json:"category"`
+	CategoryLevel1      string `json:"categoryLevel1"`
+	CategoryLevel2      string `json:"categoryLevel2"`
+	CategoryLevel3      string `json:"categoryLevel3"`
+	CategoryLevel4      string `json:"categoryLevel4"`
+	CustomCategoryTitle string `json:"customCategoryTitle"`
+
+	Country      string `json:"country"`
+	OriginLevel1 string `json:"originLevel1"`
+	OriginLevel2 string `json:"originLevel2"`
+
+	// Assortment is a short code of the assortment, such as "FS".
+	Assortment string `json:"assortment"`
+	// AssortmentText describes the assortment, such as "Fast sortiment".
+	AssortmentText    string `json:"assortmentText"`
+	ProductLaunchDate Date   `json:"productLaunchDate"`
+	Vintage           string `json:"vintage"`
+
+	// Price (SEK).
+	Price float64 `json:"price"`
+	// RecycleFee (SEK).
+	RecycleFee float64 `json:"recycleFee"`
+	// Volume (milliliters).
+	Volume                   float64 `json:"volume"`
+	VolumeText               string  `json:"volumeText"`
+	AlcoholPercentage        float64 `json:"alcoholPercentage"`
+	SugarContent             float64 `json:"sugarContent"`
+	SugarContentGramPer100ml float64 `json:"sugarContentGramPer100ml"`
+
+	BottleText               string   `json:"bottleText"`
+	PackagingLevel1          string   `json:"packagingLevel1"`
+	Seal                     []string `json:"seal"`
+	RestrictedParcelQuantity int      `json:"restrictedParcelQuantity"`
+
+	Color        string   `json:"color"`
+	Taste        string   `json:"taste"`
+	Usage        string   `json:"usage"`
+	Grapes       []string `json:"grapes"`
+	TasteSymbols []string `json:"tasteSymbols"`
+	EthicalLabel string   `json:"ethicalLabel"`
+
+	TasteClockBitter         int          `json:"tasteClockBitter"`
+	TasteClockBody           int          `json:"tasteClockBody"`
+	TasteClockCasque         int          `json:"tasteClockCasque"`
+	TasteClockFruitacid      int          `json:"tasteClockFruitacid"`
+	TasteClockRoughness      int          `json:"tasteClockRoughness"`
+	TasteClockSmokiness      int          `json:"tasteClockSmokiness"`
+	TasteClockSweetness      int          `json:"tasteClockSweetness"`
+	TasteClockGroupBitter    string       `json:"tasteClockGroupBitter"`
+	TasteClockGroupSmokiness string       `json:"tasteClockGroupSmokiness"`
+	TasteClocks              []TasteClock `json:"tasteClocks"`
+
+	Images []ProductDetailsImage `json:"images"`
+
+	IsClimateSmartPackaging         bool `json:"isClimateSmartPackaging"`
+	IsCompletelyOutOfStock          bool `json:"isCompletelyOutOfStock"`
+	IsDiscontinued                  bool `json:"isDiscontinued"`
+	IsEthical                       bool `json:"isEthical"`
+	IsKosher                        bool `json:"isKosher"`
+	IsManufacturingCountry          bool `json:"isManufacturingCountry"`
+	IsNews                          bool `json:"isNews"`
+	IsOrganic                       bool `json:"isOrganic"`
+	IsRegionalRestricted            bool `json:"isRegionalRestricted"`
+	IsSupplierTemporaryNotAvailable bool `json:"isSupplierTemporaryNotAvailable"`
+	IsSustainableChoice             bool `json:"isSustainableChoice"`
+	IsTemporaryOutOfStock           bool `json:"isTemporaryOutOfStock"`
+	IsWebLaunch                     bool `json:"isWebLaunch"`
+
+	// Ignored fields:
+	// DishPoints ?? `json:"dishPoints"` // Always null?
+	// OtherSelections ?? `json:"otherSelections"` // Always null?
+}
+
+// Decode converts the product into [ProductDetails].
+// Unknown fields are ignored and missing fields are left empty. If a field
+// can't be decoded, such as when its type has changed, an error is returned
+// alongside the partially decoded details.
+func (p Product) Decode() (*ProductDetails, error) {
+	// The vintage has been seen both as a string and a number
+	if vintage, ok := p["vintage"].(float64); ok {
+		p = maps.Clone(p)
+		p["vintage"] = strconv.FormatFloat(vintage, 'f', -1, 64)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var details ProductDetails
+	if err := json.Unmarshal(data, &details); err != nil {
+		return &details, err
+	}
+
+	return &details, nil
+}
@@ -0,0 +1,50 @@
+package systembolaget
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductDecode(t *testing.T) {
+	data, err := os.ReadFile("../samples/search.json")
+	require.NoError(t, err)
+
+	var product Product
+	require.NoError(t, json.Unmarshal(data, &product))
+	product["unknownField"] = "value"
+
+	details, err := product.Decode()
+	require.NoError(t, err)
+
+	assert.Equal(t, "831123", details.ProductID)
+	assert.Equal(t, "125303", details.ProductNumber)
+	assert.Equal(t, "Melleruds", details.ProductNameBold)
+	assert.Equal(t, "Spendrups", details.ProducerName)
+	assert.Equal(t, "Öl", details.CategoryLevel1)
+	assert.Equal(t, "", details.CategoryLevel4)
+	assert.Equal(t, time.Date(2014, 6, 2, 0, 0, 0, 0, time.UTC), details.ProductLaunchDate.Time)
+	assert.Equal(t, 15.9, details.Price)
+	assert.Equal(t, 6, details.TasteClockBody)
+	assert.Equal(t, []TasteClock{{"TasteClockBitter", 6}, {"TasteClockBody", 6}, {"TasteClockSweetness", 1}}, details.TasteClocks)
+	assert.Equal(t, []string{"Fläsk", "Fisk", "Buffémat", "Sällskapsdryck"}, details.TasteSymbols)
+	assert.True(t, details.IsOrganic)
+	assert.False(t, details.IsKosher)
+}
+
+func TestProductDecodeVintage(t *testing.T) {
+	details, err := Product{"vintage": float64(2019)}.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "2019", details.Vintage)
+}
+
+func TestProductDecodeMismatchedType(t *testing.T) {
+	details, err := Product{"productId": "1", "price": "free"}.Decode()
+	assert.Error(t, err)
+	require.NotNil(t, details)
+	assert.Equal(t, "1", details.ProductID)
+}
@@ -0,0 +1,462 @@
+package systembolaget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query is a search described using the query language, see [ParseQuery].
+type Query struct {
+	Options SearchOptions
+	Filters []SearchFilter
+}
+
+// QuerySyntaxError is returned by [ParseQuery] for invalid queries.
+type QuerySyntaxError struct {
+	// Offset is the byte offset of the error in the query.
+	Offset int
+	// Column is the 1-based position of the error in the query, in runes.
+	Column  int
+	Message string
+}
+
+// Error implements error.
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at column %d: %s", e.Column, e.Message)
+}
+
+// sortProperties are the valid values of the "sort" key.
+var sortProperties = []SortProperty{
+	SortPropertyScore,
+	SortPropertyPrice,
+	SortPropertyName,
+	SortPropertyVolume,
+	SortPropertyProductLaunchDate,
+	SortPropertyVintage,
+}
+
+// ParseQuery parses a search written using the query language, such as:
+//
+//	category:Öl/"Ljus lager" origin:Sverige price:10..40 abv:..5 launched:>=2025-01-01 sort:-ProductLaunchDate
+//
+// A query consists of whitespace separated terms. Each term is a key, as
+// named by the [FilterKind] constants, followed by a colon and a value. Values
+// containing whitespace or special characters are quoted using double quotes.
+// Terms without a key are used as a free text query, combined with any query
+// terms.
+//
+//   - Hierarchical filters, such as category and packaging, separate levels
+//     using "/".
+//   - Range filters, such as price, take "min..max", where either end may be
+//     left out, ">=min", "<=max" or a single value.
+//   - The param filter takes "name=value".
+//   - The sort key takes a [SortProperty], prefixed with "-" to sort in
+//     descending order.
+//   - The page and size keys set the page and page size.
+func ParseQuery(query string) (*Query, error) {
+	p := &queryParser{input: query}
+	return p.parse()
+}
+
+// String returns the query using the query language. Parsing the returned
+// string with [ParseQuery] yields an equivalent query.
+func (q *Query) String() string {
+	terms := make([]string, 0, len(q.Filters)+3)
+	for _, filter := range q.Filters {
+		if term := filter.String(); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	if q.Options.SortBy != "" {
+		prefix := ""
+		if q.Options.SortDirection == SortDirectionDescending {
+			prefix = "-"
+		}
+		terms = append(terms, "sort:"+prefix+string(q.Options.SortBy))
+	}
+
+	if q.Options.Page > 0 {
+		terms = append(terms, "page:"+formatInt(q.Options.Page))
+	}
+
+	if q.Options.PageSize > 0 {
+		terms = append(terms, "size:"+formatInt(q.Options.PageSize))
+	}
+
+	return strings.Join(terms, " ")
+}
+
+type queryParser struct {
+	input string
+	pos   int
+}
+
+func (p *queryParser) errorf(offset int, format string, args ...any) error {
+	return &QuerySyntaxError{
+		Offset:  offset,
+		Column:  utf8.RuneCountInString(p.input[:offset]) + 1,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return r
+}
+
+func (p *queryParser) skipWhitespace() {
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+// key reads a key followed by a colon. Returns false without consuming any
+// input if the upcoming term has no key.
+func (p *queryParser) key() (string, bool) {
+	end := p.pos
+	for end < len(p.input) {
+		c := p.input[end]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		end++
+	}
+
+	if end == p.pos || end >= len(p.input) || p.input[end] != ':' {
+		return "", false
+	}
+
+	key := p.input[p.pos:end]
+	p.pos = end + 1
+	return key, true
+}
+
+// value reads a quoted value or a bare value ending at whitespace or any of
+// the stop characters.
+func (p *queryParser) value(stop string) (string, error) {
+	start := p.pos
+	if p.eof() || unicode.IsSpace(p.peek()) {
+		return "", p.errorf(start, "expected value")
+	}
+
+	if p.input[p.pos] == '"' {
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		if end >= len(p.input) {
+			return "", p.errorf(start, "unterminated quoted value")
+		}
+
+		value, err := strconv.Unquote(p.input[p.pos : end+1])
+		if err != nil {
+			return "", p.errorf(start, "invalid quoted value")
+		}
+
+		p.pos = end + 1
+		return value, nil
+	}
+
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || strings.ContainsRune(stop, r) {
+			break
+		}
+		if r == '"' {
+			return "", p.errorf(p.pos, "unexpected quote")
+		}
+		p.pos += size
+	}
+
+	if p.pos == start {
+		return "", p.errorf(start, "expected value")
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+// endOfTerm ensures that the current term has ended.
+func (p *queryParser) endOfTerm() error {
+	if !p.eof() && !unicode.IsSpace(p.peek()) {
+		return p.errorf(p.pos, "unexpected %q", p.peek())
+	}
+	return nil
+}
+
+func (p *queryParser) parse() (*Query, error) {
+	query := &Query{Filters: make([]SearchFilter, 0)}
+	text := make([]string, 0)
+	textIndex := -1
+
+	// Free text and query terms are collected into a single query filter, as
+	// they would otherwise replace each other
+	addText := func(value string) {
+		if textIndex == -1 {
+			textIndex = len(query.Filters)
+			query.Filters = append(query.Filters, SearchFilter{})
+		}
+		if value != "" {
+			text = append(text, value)
+		}
+	}
+
+	for {
+		p.skipWhitespace()
+		if p.eof() {
+			break
+		}
+
+		start := p.pos
+		key, ok := p.key()
+		if !ok {
+			value, err := p.value("")
+			if err != nil {
+				return nil, err
+			}
+			if err := p.endOfTerm(); err != nil {
+				return nil, err
+			}
+
+			addText(value)
+			continue
+		}
+
+		switch key {
+		case "sort":
+			valueStart := p.pos
+			value, err := p.value("")
+			if err != nil {
+				return nil, err
+			}
+
+			direction := SortDirectionAscending
+			if trimmed, ok := strings.CutPrefix(value, "-"); ok {
+				direction = SortDirectionDescending
+				value = trimmed
+			} else if trimmed, ok := strings.CutPrefix(value, "+"); ok {
+				value = trimmed
+			}
+
+			property, ok := lookupSortProperty(value)
+			if !ok {
+				return nil, p.errorf(valueStart, "unknown sort property %q", value)
+			}
+
+			query.Options.SortBy = property
+			query.Options.SortDirection = direction
+		case "page", "size":
+			valueStart := p.pos
+			value, err := p.value("")
+			if err != nil {
+				return nil, err
+			}
+
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, p.errorf(valueStart, "invalid %s %q", key, value)
+			}
+
+			if key == "page" {
+				query.Options.Page = n
+			} else {
+				query.Options.PageSize = n
+			}
+		default:
+			spec, ok := lookupFilterSpec(FilterKind(key))
+			if !ok {
+				return nil, p.errorf(start, "unknown key %q", key)
+			}
+
+			filter, err := p.filter(spec)
+			if err != nil {
+				return nil, err
+			}
+
+			if spec.kind == FilterKindQuery {
+				addText(filter.args[0])
+			} else {
+				query.Filters = append(query.Filters, filter)
+			}
+		}
+
+		if err := p.endOfTerm(); err != nil {
+			return nil, err
+		}
+	}
+
+	if textIndex != -1 {
+		query.Filters[textIndex] = FilterByQuery(strings.Join(text, " "))
+	}
+
+	return query, nil
+}
+
+func lookupSortProperty(value string) (SortProperty, bool) {
+	for _, property := range sortProperties {
+		if strings.EqualFold(string(property), value) {
+			return property, true
+		}
+	}
+	return "", false
+}
+
+// filter parses the value of a filter.
+func (p *queryParser) filter(spec filterSpec) (SearchFilter, error) {
+	switch spec.shape {
+	case shapeLevels:
+		levels := make([]string, 0, spec.maxArgs)
+		for {
+			if len(levels) == spec.maxArgs {
+				return SearchFilter{}, p.errorf(p.pos, "too many levels, expected at most %d", spec.maxArgs)
+			}
+
+			value, err := p.value("/")
+			if err != nil {
+				return SearchFilter{}, err
+			}
+			levels = append(levels, value)
+
+			if p.eof() || p.input[p.pos] != '/' {
+				break
+			}
+			p.pos++
+		}
+		return newFilter(spec.kind, levels...), nil
+	case shapeParameter:
+		name, err := p.value("=")
+		if err != nil {
+			return SearchFilter{}, err
+		}
+
+		if p.eof() || p.input[p.pos] != '=' {
+			return SearchFilter{}, p.errorf(p.pos, "expected '='")
+		}
+		p.pos++
+
+		value, err := p.value("")
+		if err != nil {
+			return SearchFilter{}, err
+		}
+		return newFilter(spec.kind, name, value), nil
+	case shapeRange:
+		start := p.pos
+		if _, err := p.value(""); err != nil {
+			return SearchFilter{}, err
+		}
+
+		// Split the raw value to keep track of the offset of each end
+		raw, offset := p.input[start:p.pos], start
+		quoted := strings.HasPrefix(raw, `"`)
+		if quoted {
+			raw, offset = raw[1:len(raw)-1], start+1
+		}
+
+		minimum := rangeBound{value: raw, offset: offset}
+		maximum := minimum
+		if v, ok := strings.CutPrefix(raw, ">="); ok {
+			minimum = rangeBound{value: v, offset: offset + 2}
+			maximum = rangeBound{}
+		} else if v, ok := strings.CutPrefix(raw, "<="); ok {
+			minimum = rangeBound{}
+			maximum = rangeBound{value: v, offset: offset + 2}
+		} else if before, after, ok := strings.Cut(raw, ".."); ok {
+			minimum = rangeBound{value: before, offset: offset}
+			maximum = rangeBound{value: after, offset: offset + len(before) + 2}
+		}
+
+		if minimum.value == "" && maximum.value == "" {
+			return SearchFilter{}, p.errorf(start, "expected a range such as 10..40")
+		}
+
+		for _, bound := range []*rangeBound{&minimum, &maximum} {
+			if bound.value == "" {
+				continue
+			}
+			if quoted {
+				v, err := strconv.Unquote(`"` + bound.value + `"`)
+				if err != nil {
+					return SearchFilter{}, p.errorf(bound.offset, "invalid quoted value")
+				}
+				bound.value = v
+			}
+			if err := validateRangeValue(spec.kind, bound.value); err != nil {
+				return SearchFilter{}, p.errorf(bound.offset, "%s", err)
+			}
+		}
+
+		return newFilter(spec.kind, minimum.value, maximum.value), nil
+	default:
+		start := p.pos
+		value, err := p.value("")
+		if err != nil {
+			return SearchFilter{}, err
+		}
+
+		if spec.kind == FilterKindVintage {
+			if _, err := strconv.Atoi(value); err != nil {
+				return SearchFilter{}, p.errorf(start, "invalid vintage %q", value)
+			}
+		}
+
+		return newFilter(spec.kind, value), nil
+	}
+}
+
+// rangeBound is one end of a range filter and its byte offset in the query.
+type rangeBound struct {
+	value  string
+	offset int
+}
+
+// validateRangeValue validates one end of a range filter.
+func validateRangeValue(kind FilterKind, value string) error {
+	switch kind {
+	case FilterKindProductLaunch:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+		}
+	case FilterKindTasteClockBody, FilterKindTasteClockBitterness, FilterKindTasteClockSweetness, FilterKindTasteClockSmokiness:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+	default:
+		if !isDecimal(value) {
+			return fmt.Errorf("invalid number %q", value)
+		}
+	}
+	return nil
+}
+
+// isDecimal returns whether or not value is a number in plain decimal form,
+// such as "10" or "-2.5". Forms such as "Inf", "1e3" and "0." are rejected, as
+// they're either not understood by the API or can't be printed as a range.
+func isDecimal(value string) bool {
+	integer, fraction, hasFraction := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+	if integer == "" || (hasFraction && fraction == "") {
+		return false
+	}
+
+	for _, r := range integer + fraction {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
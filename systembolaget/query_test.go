@@ -0,0 +1,101 @@
+package systembolaget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	query, err := ParseQuery(`category:Öl/"Ljus lager" origin:Sverige price:10..40 abv:..5 launched:>=2025-01-01 sort:-ProductLaunchDate pilsner "tysk stil"`)
+	require.NoError(t, err)
+
+	assert.Equal(t, SearchOptions{
+		SortBy:        SortPropertyProductLaunchDate,
+		SortDirection: SortDirectionDescending,
+	}, query.Options)
+
+	assert.Equal(t, []SearchFilter{
+		FilterByCategory("Öl", "Ljus lager", ""),
+		FilterByOrigin("Sverige"),
+		FilterByPrice(10, 40),
+		newFilter(FilterKindAlcoholPercentage, "", "5"),
+		newFilter(FilterKindProductLaunch, "2025-01-01", ""),
+		FilterByQuery("pilsner tysk stil"),
+	}, query.Filters)
+
+	values := FiltersToValues(query.Filters...)
+	assert.Equal(t, "5", values.Get("alcoholPercentage.max"))
+	assert.False(t, values.Has("alcoholPercentage.min"))
+
+	expected := `category:Öl/"Ljus lager" origin:Sverige price:10..40 abv:..5 launched:2025-01-01.. query:"pilsner tysk stil" sort:-ProductLaunchDate`
+	assert.Equal(t, expected, query.String())
+
+	reparsed, err := ParseQuery(query.String())
+	require.NoError(t, err)
+	assert.Equal(t, query, reparsed)
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	testCases := []struct {
+		Query  string
+		Column int
+	}{
+		{Query: `unknown:value`, Column: 1},
+		{Query: `origin:Sverige price:abc`, Column: 22},
+		{Query: `price:10..x`, Column: 11},
+		{Query: `category:"Öl`, Column: 10},
+		{Query: `öl sort:Färg`, Column: 9},
+		{Query: `launched:2025-13-01..`, Column: 10},
+		{Query: `origin:`, Column: 8},
+		{Query: `param:name`, Column: 11},
+		{Query: `category:a/b/c/d/e`, Column: 18},
+		{Query: `category:"Öl"x`, Column: 14},
+		{Query: `price:"10..x"`, Column: 12},
+		{Query: `price:"x"`, Column: 8},
+		{Query: `body:15..5.`, Column: 10},
+		{Query: `body:"5..5."`, Column: 10},
+		{Query: `abv:Inf`, Column: 5},
+		{Query: `price:NaN`, Column: 7},
+		{Query: `price:0.`, Column: 7},
+		{Query: `price:10..1e3`, Column: 11},
+		{Query: `price:.5`, Column: 7},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Query, func(t *testing.T) {
+			_, err := ParseQuery(testCase.Query)
+			var syntaxErr *QuerySyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, testCase.Column, syntaxErr.Column, syntaxErr.Error())
+		})
+	}
+}
+
+func TestParseQueryRanges(t *testing.T) {
+	query, err := ParseQuery(`price:"10..40" volume:5..5 abv:"<=5.5" sugar:-1..0.25`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SearchFilter{
+		FilterByPrice(10, 40),
+		newFilter(FilterKindVolume, "5", "5"),
+		newFilter(FilterKindAlcoholPercentage, "", "5.5"),
+		newFilter(FilterKindSugarContent, "-1", "0.25"),
+	}, query.Filters)
+
+	reparsed, err := ParseQuery(query.String())
+	require.NoError(t, err)
+	assert.Equal(t, query, reparsed)
+}
+
+func TestParseQueryText(t *testing.T) {
+	query, err := ParseQuery(`query:foo origin:Sverige bar query:"baz qux"`)
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByQuery("foo bar baz qux"), FilterByOrigin("Sverige")}, query.Filters)
+	assert.Equal(t, "foo bar baz qux", FiltersToValues(query.Filters...).Get("textQuery"))
+
+	query, err = ParseQuery(`query:""`)
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByQuery("")}, query.Filters)
+}
@@ -0,0 +1,112 @@
+package systembolaget
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out requests so that at most one request is started per
+// interval. A single limiter may be shared by several clients, in which case
+// the limit applies to all of them combined.
+//
+// A nil *RateLimiter does not limit requests.
+type RateLimiter struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+	// released holds slots given back by cancelled waits, which are reused
+	// before reserving new ones.
+	released []time.Time
+}
+
+// NewRateLimiter returns a [RateLimiter] allowing requestsPerSecond requests
+// per second. A value of zero or less disables rate limiting.
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+// Wait blocks until a request may be started or ctx is done. If ctx is done
+// first, the reserved slot is given back for use by other requests.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	now := time.Now()
+	start := l.reserve(now)
+
+	if err := ctx.Err(); err != nil {
+		l.release(start)
+		return err
+	}
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release(start)
+		return ctx.Err()
+	}
+}
+
+// reserve returns the start of the next free slot, preferring the earliest
+// released slot that has not yet passed.
+func (l *RateLimiter) reserve(now time.Time) time.Time {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Passed slots can't be reused without starting too close to the next slot
+	l.released = slices.DeleteFunc(l.released, func(slot time.Time) bool {
+		return !slot.After(now)
+	})
+
+	if len(l.released) > 0 {
+		i := 0
+		for j, slot := range l.released {
+			if slot.Before(l.released[i]) {
+				i = j
+			}
+		}
+
+		start := l.released[i]
+		l.released = slices.Delete(l.released, i, i+1)
+		return start
+	}
+
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	return start
+}
+
+// release gives back the slot starting at start.
+func (l *RateLimiter) release(start time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.next.Equal(start.Add(l.interval)) {
+		// The slot is the last one reserved
+		l.next = start
+	} else if start.After(time.Now()) {
+		l.released = append(l.released, start)
+	}
+}
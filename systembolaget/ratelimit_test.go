@@ -0,0 +1,79 @@
+package systembolaget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100)
+
+	start := time.Now()
+	for range 5 {
+		require.NoError(t, limiter.Wait(context.TODO()))
+	}
+
+	// The first request is immediate, the remaining four are spaced 10ms apart
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	var limiter *RateLimiter
+	assert.NoError(t, limiter.Wait(context.TODO()))
+	assert.Nil(t, NewRateLimiter(0))
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	limiter := NewRateLimiter(0.1)
+	require.NoError(t, limiter.Wait(context.TODO()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestRateLimiterCancelledReleasesSlot(t *testing.T) {
+	limiter := NewRateLimiter(0.1)
+	require.NoError(t, limiter.Wait(context.TODO()))
+
+	next := func() time.Time {
+		limiter.mutex.Lock()
+		defer limiter.mutex.Unlock()
+		return limiter.next
+	}
+	first := next()
+
+	// The last slot is given back by moving the next slot back
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+	assert.Equal(t, first, next())
+
+	// Earlier slots are reused by later waits
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	errB := make(chan error)
+	go func() { errB <- limiter.Wait(ctxB) }()
+	require.Eventually(t, func() bool { return next().After(first) }, time.Second, time.Millisecond)
+
+	ctxC, cancelC := context.WithCancel(context.Background())
+	defer cancelC()
+	errC := make(chan error)
+	go func() { errC <- limiter.Wait(ctxC) }()
+	last := first.Add(limiter.interval)
+	require.Eventually(t, func() bool { return next().After(last) }, time.Second, time.Millisecond)
+	last = next()
+
+	cancelB()
+	assert.ErrorIs(t, <-errB, context.Canceled)
+
+	assert.Equal(t, first, limiter.reserve(time.Now()))
+	assert.Equal(t, last, next())
+
+	cancelC()
+	assert.ErrorIs(t, <-errC, context.Canceled)
+}
@@ -0,0 +1,144 @@
+package systembolaget
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how failed requests are retried.
+//
+// Only idempotent requests (GET and HEAD) are retried and only if they failed
+// due to network errors or retryable status codes, see [APIError.Retryable].
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. The delay is doubled
+	// for each subsequent retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts, including delays
+	// requested by the server using the Retry-After header. Defaults to 30s.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a sensible retry policy for most use cases.
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+// The delay is randomized to between half and all of the exponential backoff.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+
+	delay := initial << (retry - 1)
+	if delay <= 0 || delay > p.maxBackoff() {
+		delay = p.maxBackoff()
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return 30 * time.Second
+	}
+	return p.MaxBackoff
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// isIdempotent returns whether or not a request may be safely retried.
+func isIdempotent(req *http.Request) bool {
+	return req.Method == "" || req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// do performs a request using client, retrying it according to policy.
+// Each attempt waits for limiter, if any.
+// Returns an [*APIError] if the final response has a status code other than
+// 200 OK. A nil policy disables retries.
+func do(client *http.Client, policy *RetryPolicy, limiter *RateLimiter, req *http.Request) (*http.Response, error) {
+	maxAttempts := 1
+	if policy != nil && policy.MaxAttempts > 1 && isIdempotent(req) {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		res, err := client.Do(req)
+		if err == nil && res.StatusCode == http.StatusOK {
+			return res, nil
+		}
+
+		retryAfter := ""
+		if err == nil {
+			apiErr := newAPIError(res)
+			res.Body.Close()
+			err = apiErr
+
+			if !apiErr.Retryable() {
+				return nil, err
+			}
+
+			retryAfter = res.Header.Get("Retry-After")
+		} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+
+		// Retries require a policy, so policy is non-nil past this point
+		if attempt >= maxAttempts {
+			return nil, err
+		}
+
+		delay, ok := parseRetryAfter(retryAfter, time.Now())
+		if ok {
+			delay = min(delay, policy.maxBackoff())
+		} else {
+			delay = policy.backoff(attempt)
+		}
+
+		slog.Debug("Retrying failed request", slog.String("url", req.URL.Redacted()), slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", err))
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -146,8 +145,15 @@ type ProductImage struct {
 	// Size ?? `json:"size"` // Always null?
 }
 
-// Images returns references to images of the product.
+// Images returns references to images of the product, served by
+// [DefaultWebBaseURL].
 func (p Product) Images() ([]ProductImage, bool) {
+	return p.ImagesWithBaseURL(DefaultWebBaseURL)
+}
+
+// ImagesWithBaseURL returns references to images of the product, served by the
+// image proxy of the website at webBaseURL. Defaults to [DefaultWebBaseURL].
+func (p Product) ImagesWithBaseURL(webBaseURL string) ([]ProductImage, bool) {
 	images, ok := p["images"].([]any)
 	if !ok || images == nil {
 		return nil, false
@@ -179,11 +185,9 @@ func (p Product) Images() ([]ProductImage, bool) {
 		query.Set("w", "2000")
 		query.Set("q", "75")
 
-		u := url.URL{
-			Scheme:   "https",
-			Host:     "www.systembolaget.se",
-			Path:     "/_next/image/",
-			RawQuery: query.Encode(),
+		u, err := resolveURL(webBaseURL, DefaultWebBaseURL, "/_next/image/", query)
+		if err != nil {
+			return nil, false
 		}
 
 		result = append(result, ProductImage{URL: u.String()})
@@ -343,155 +347,107 @@ type SearchOptions struct {
 	// SortDirection specifies the direction in which to sort. "Ascending" or
 	// "Descending".
 	SortDirection SortDirection
-}
 
-// SearchFilter describes filters that modifies the search query.
-type SearchFilter func(*url.Values)
+	// PageDelay is the delay between fetching two pages when iterating over
+	// results using [AuthenticatedClient.Pages] or [AuthenticatedClient.Products].
+	PageDelay time.Duration
+}
 
 // FilterByStore filters products that are in a specific store's assortment.
 func FilterByStore(store string) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("storeId", store)
-		v.Set("isInStoreAssortmentSearch", "true")
-	}
+	return newFilter(FilterKindStore, store)
 }
 
 // FilterByQuery queries products using free text.
 func FilterByQuery(query string) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("textQuery", query)
-	}
+	return newFilter(FilterKindQuery, query)
 }
 
 // FilterByTasteClockBody filters products of a certain body where 0 (minimum)
 // is a thin body and 12 (maximum) is a full body.
 func FilterByTasteClockBody(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("tasteClockBody.min", strconv.FormatInt(int64(min), 10))
-		v.Set("tasteClockBody.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindTasteClockBody, formatInt(min), formatInt(max))
 }
 
 // FilterByTasteClockBitterness filters products of a certain bitterness where 0
 // (minimum) is not bitter at all and 12 (maximum) is very bitter.
 func FilterByTasteClockBitterness(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("tasteClockBitter.min", strconv.FormatInt(int64(min), 10))
-		v.Set("tasteClockBitter.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindTasteClockBitterness, formatInt(min), formatInt(max))
 }
 
 // FilterByTasteClockSweetness filters products of a certain sweetness where 0
 // (minimum) is not sweet at all and 12 (maximum) is very sweet.
 func FilterByTasteClockSweetness(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("tasteClockSweetness.min", strconv.FormatInt(int64(min), 10))
-		v.Set("tasteClockSweetness.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindTasteClockSweetness, formatInt(min), formatInt(max))
 }
 
 // FilterByTasteClockSmokiness filters products of a certain smokiness where 0
 // (minimum) is not smoky at all and 12 (maximum) is very smoky.
 func FilterByTasteClockSmokiness(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("tasteClockSmokiness.min", strconv.FormatInt(int64(min), 10))
-		v.Set("tasteClockSmokiness.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindTasteClockSmokiness, formatInt(min), formatInt(max))
 }
 
 // FilterByVintage may be used more than once.
 func FilterByVintage(vintage int) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("vintage", strconv.FormatInt(int64(vintage), 10))
-	}
+	return newFilter(FilterKindVintage, formatInt(vintage))
 }
 
 func FilterByProductLaunch(min time.Time, max time.Time) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("productLaunch.min", min.Format("2006-01-02"))
-		v.Set("productLaunch.max", max.Format("2006-01-02"))
-	}
+	return newFilter(FilterKindProductLaunch, min.Format("2006-01-02"), max.Format("2006-01-02"))
 }
 
 func FilterByAlcoholPercentage(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("alcoholPercentage.min", strconv.FormatInt(int64(min), 10))
-		v.Set("alcoholPercentage.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindAlcoholPercentage, formatInt(min), formatInt(max))
 }
 
 func FilterBySugarContent(min float32, max float32) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("sugarContentGramPer100ml.min", strconv.FormatFloat(float64(min), 'f', 2, 32))
-		v.Set("sugarContentGramPer100ml.max", strconv.FormatFloat(float64(max), 'f', 2, 32))
-	}
+	return newFilter(FilterKindSugarContent, strconv.FormatFloat(float64(min), 'f', 2, 32), strconv.FormatFloat(float64(max), 'f', 2, 32))
 }
 
 // FilterByGrapes may be used more than once.
 func FilterByGrapes(grapes string) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("grapes", grapes)
-	}
+	return newFilter(FilterKindGrapes, grapes)
 }
 
 // FilterByMatch filters products that fit with a taste, such as "Aperitif",
 // "Asiatiskt" or "Kött".
 // May be used more than once.
 func FilterByMatch(match string) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("tasteSymbols", match)
-	}
+	return newFilter(FilterKindMatch, match)
 }
 
 // FilterByAssortment specifies the assortment the product should be included
 // in, such as "Fast sortiment" or "Tillfälligt sortiment".
 // May be used more than once.
 func FilterByAssortment(assortment string) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("assortmentText", assortment)
-	}
+	return newFilter(FilterKindAssortment, assortment)
 }
 
 // FilterBySeal filters products that use a specific seal, such as "A-koppling"
 // or "Champagnekork-natur".
 func FilterBySeal(seal string) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("seal", seal)
-	}
+	return newFilter(FilterKindSeal, seal)
 }
 
 func FilterByVolume(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("volume.min", strconv.FormatInt(int64(min), 10))
-		v.Set("volume.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindVolume, formatInt(min), formatInt(max))
 }
 
 // FilterByPackaging filters products that use a specific packaging, such as
 // "Flaska" + "Glasflaska".
 // Leave subcategory empty to only filter by category.
 func FilterByPackaging(category string, subcategory string) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("packagingLevel1", category)
-		if subcategory != "" {
-			v.Set("packagingLevel2", subcategory)
-		}
-	}
+	return newFilter(FilterKindPackaging, category, subcategory)
 }
 
 func FilterByPrice(min int, max int) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("price.min", strconv.FormatInt(int64(min), 10))
-		v.Set("price.max", strconv.FormatInt(int64(max), 10))
-	}
+	return newFilter(FilterKindPrice, formatInt(min), formatInt(max))
 }
 
 // FilterByOrigin filters products that originate from a specific country.
 // May be used more than once.
 func FilterByOrigin(country string) SearchFilter {
-	return func(v *url.Values) {
-		v.Add("country", country)
-	}
+	return newFilter(FilterKindOrigin, country)
 }
 
 // FilterByCategory filters products of a specific category, such as "Öl" +
@@ -500,15 +456,32 @@ func FilterByOrigin(country string) SearchFilter {
 // Can be used more than once to specify multiple subsubcategories of the same
 // category and subcategory.
 func FilterByCategory(category string, subcategory string, subsubcategory string) SearchFilter {
-	return func(v *url.Values) {
-		v.Set("categoryLevel1", category)
-		if subcategory != "" {
-			v.Set("categoryLevel2", subcategory)
-			if subsubcategory != "" {
-				v.Add("categoryLevel3", subsubcategory)
-			}
-		}
+	if subcategory == "" {
+		return newFilter(FilterKindCategory, category)
 	}
+	return newFilter(FilterKindCategory, category, subcategory, subsubcategory)
+}
+
+// FilterByParameter adds an arbitrary query parameter, such as one not yet
+// supported by the library.
+// May be used more than once.
+func FilterByParameter(name string, value string) SearchFilter {
+	return newFilter(FilterKindParameter, name, value)
+}
+
+// FilterFunc returns a filter that modifies the query parameters using fn,
+// for filters that can't be expressed using [FilterByParameter], such as ones
+// that replace or remove parameters. It replaces the function type previously
+// used for filters, so SearchFilter(fn) becomes FilterFunc(fn).
+//
+// The returned filter is only equal to itself and can't be printed or
+// serialized.
+func FilterFunc(fn func(*url.Values)) SearchFilter {
+	return SearchFilter{kind: FilterKindFunc, fn: &fn}
+}
+
+func formatInt(v int) string {
+	return strconv.FormatInt(int64(v), 10)
 }
 
 // Search searches for products.
@@ -537,23 +510,20 @@ func (c *AuthenticatedClient) Search(ctx context.Context, options *SearchOptions
 	}
 
 	for _, filter := range filters {
-		filter(&query)
+		filter.Apply(&query)
 	}
 
-	u := &url.URL{
-		Scheme:   "https",
-		Host:     "api-extern.systembolaget.se",
-		Path:     "/sb-api-ecommerce/v1/productsearch/search",
-		RawQuery: query.Encode(),
+	u, err := c.apiURL("/sb-api-ecommerce/v1/productsearch/search", query)
+	if err != nil {
+		return nil, err
 	}
 
 	header := make(http.Header)
-	header.Set("Origin", "https://www.systembolaget.se")
+	header.Set("Origin", webOrigin(c.WebBaseURL))
 	header.Set("Access-Control-Allow-Origin", "*")
 	header.Set("Pragma", "no-cache")
 	header.Set("Accept", "application/json")
 	header.Set("Cache-Control", "no-cache")
-	header.Set("Ocp-Apim-Subscription-Key", c.APIKey)
 
 	if c.UserAgent != "" {
 		header.Set("User-Agent", c.UserAgent)
@@ -565,16 +535,12 @@ func (c *AuthenticatedClient) Search(ctx context.Context, options *SearchOptions
 		Header: header,
 	}).Clone(ctx)
 
-	res, err := c.Client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d - %s", res.StatusCode, res.Status)
-	}
-
 	var result SearchResult
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&result); err != nil {
@@ -17,8 +17,6 @@ func TestSearchWithCursor(t *testing.T) {
 
 	yieldedItems := 0
 	for cursor.Next(context.TODO(), 0) {
-		require.NoError(t, cursor.Error())
-
 		product := cursor.At()
 
 		assert.NotNil(t, product)
@@ -26,6 +24,7 @@ func TestSearchWithCursor(t *testing.T) {
 
 		yieldedItems++
 	}
+	require.NoError(t, cursor.Error())
 
 	// Ensure that we retrieved all products
 	assert.Equal(t, cursor.CurrentPage().Metadata.FullAssortmentDocumentCount, yieldedItems)
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 )
 
 type StockStatus struct {
@@ -18,18 +20,16 @@ type StockStatus struct {
 
 // GetStockStatus fetches the stock status of a product in a specific store.
 func (c *AuthenticatedClient) GetStockStatus(ctx context.Context, storeID string, productID string) (*StockStatus, error) {
-	u := &url.URL{
-		Scheme: "https",
-		Host:   "api-extern.systembolaget.se",
-		Path:   fmt.Sprintf("/sb-api-ecommerce/v1/stockbalance/store/%s/%s/", storeID, productID),
+	u, err := c.apiURL(fmt.Sprintf("/sb-api-ecommerce/v1/stockbalance/store/%s/%s/", url.PathEscape(storeID), url.PathEscape(productID)), nil)
+	if err != nil {
+		return nil, err
 	}
 
 	header := make(http.Header)
-	header.Set("Origin", "https://www.systembolaget.se")
+	header.Set("Origin", webOrigin(c.WebBaseURL))
 	header.Set("Pragma", "no-cache")
 	header.Set("Accept", "application/json")
 	header.Set("Cache-Control", "no-cache")
-	header.Set("Ocp-Apim-Subscription-Key", c.APIKey)
 
 	if c.UserAgent != "" {
 		header.Set("User-Agent", c.UserAgent)
@@ -41,16 +41,12 @@ func (c *AuthenticatedClient) GetStockStatus(ctx context.Context, storeID string
 		Header: header,
 	}).Clone(ctx)
 
-	res, err := c.Client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d - %s", res.StatusCode, res.Status)
-	}
-
 	var status StockStatus
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&status); err != nil {
@@ -59,3 +55,50 @@ func (c *AuthenticatedClient) GetStockStatus(ctx context.Context, storeID string
 
 	return &status, nil
 }
+
+// GetStockAcrossStores fetches the stock status of a product in each of the
+// given stores. Duplicate stores are fetched once.
+//
+// Requests are made concurrently, bounded by the client's Concurrency and
+// limited by its RateLimiter. The returned statuses are sorted by stock, in
+// descending order. If any request fails, a [*BatchError] holding the error of
+// each failed store, keyed by store id, is returned along with the statuses
+// that were fetched.
+func (c *AuthenticatedClient) GetStockAcrossStores(ctx context.Context, productID string, storeIDs []string) ([]StockStatus, error) {
+	statuses, errs := batch(ctx, c.Concurrency, storeIDs, func(ctx context.Context, storeID string) (*StockStatus, error) {
+		return c.GetStockStatus(ctx, storeID, productID)
+	})
+
+	result := make([]StockStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+
+	slices.SortFunc(result, func(a StockStatus, b StockStatus) int {
+		if a.Stock != b.Stock {
+			return b.Stock - a.Stock
+		}
+		return strings.Compare(a.StoreID, b.StoreID)
+	})
+
+	return result, newBatchError(errs)
+}
+
+// GetStockInAllStores fetches the stock status of a product in all stores
+// using [AuthenticatedClient.GetStockAcrossStores]. Agents, which don't keep
+// stock, are excluded.
+func (c *AuthenticatedClient) GetStockInAllStores(ctx context.Context, productID string) ([]StockStatus, error) {
+	stores, err := c.GetStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeIDs := make([]string, 0, len(stores))
+	for _, store := range stores {
+		if !store.IsAgent {
+			storeIDs = append(storeIDs, store.SiteID)
+		}
+	}
+
+	return c.GetStockAcrossStores(ctx, productID, storeIDs)
+}
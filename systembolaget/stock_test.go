@@ -2,9 +2,13 @@ package systembolaget
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +21,75 @@ func TestAuthenticatedClient_GetStockStatus(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Printf("%+v\n", status)
 }
+
+func newStockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	stock := map[string]int{"0101": 3, "0102": 12, "0103": 0, "0104": 12}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sb-api-ecommerce/v1/stockbalance/store/{storeId}/{productId}/", func(w http.ResponseWriter, r *http.Request) {
+		storeID := r.PathValue("storeId")
+		count, ok := stock[storeID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(&StockStatus{
+			ProductID:           r.PathValue("productId"),
+			StoreID:             storeID,
+			Shelf:               "Öl " + storeID,
+			Stock:               count,
+			IsInStoreAssortment: true,
+		})
+	})
+	mux.HandleFunc("/sb-api-ecommerce/v1/sitesearch/site", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"siteSearchResults": []Store{
+				{SiteID: "0101"},
+				{SiteID: "0102"},
+				{SiteID: "0104"},
+				{SiteID: "9999", IsAgent: true},
+			},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthenticatedClient_GetStockAcrossStores(t *testing.T) {
+	server := newStockServer(t)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	statuses, err := client.GetStockAcrossStores(context.TODO(), "507849", []string{"0103", "0101", "0102", "0104", "0404", "0101"})
+
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 1)
+	assert.ErrorIs(t, batchErr.Errors["0404"], ErrNotFound)
+
+	storeIDs := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		storeIDs = append(storeIDs, status.StoreID)
+		assert.Equal(t, "507849", status.ProductID)
+		assert.Equal(t, "Öl "+status.StoreID, status.Shelf)
+	}
+	assert.Equal(t, []string{"0102", "0104", "0101", "0103"}, storeIDs)
+}
+
+func TestAuthenticatedClient_GetStockInAllStores(t *testing.T) {
+	server := newStockServer(t)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	statuses, err := client.GetStockInAllStores(context.TODO(), "507849")
+	require.NoError(t, err)
+
+	storeIDs := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		storeIDs = append(storeIDs, status.StoreID)
+	}
+	assert.Equal(t, []string{"0102", "0104", "0101"}, storeIDs)
+}
@@ -0,0 +1,79 @@
+package systembolaget
+
+import (
+	"context"
+)
+
+// StockMatrix holds the stock status of a number of products in a number of
+// stores, see [AuthenticatedClient.GetStockMatrix].
+type StockMatrix struct {
+	ProductIDs []string `json:"productIds"`
+	StoreIDs   []string `json:"storeIds"`
+	// Statuses holds the status of each product in each store, indexed by
+	// product and then by store in the order of ProductIDs and StoreIDs.
+	// A status is nil if it could not be fetched.
+	Statuses [][]*StockStatus `json:"statuses"`
+}
+
+// Status returns the status of a product in a store, if it was fetched.
+func (m *StockMatrix) Status(productID string, storeID string) (*StockStatus, bool) {
+	for i, id := range m.ProductIDs {
+		if id != productID {
+			continue
+		}
+
+		for j, id := range m.StoreIDs {
+			if id == storeID && m.Statuses[i][j] != nil {
+				return m.Statuses[i][j], true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// stockKey identifies a product in a store.
+type stockKey struct {
+	productID string
+	storeID   string
+}
+
+// GetStockMatrix fetches the stock status of each of the products in each of
+// the stores. Duplicate products and stores are fetched once.
+//
+// Requests are made concurrently, bounded by the client's Concurrency and
+// limited by its RateLimiter. If any request fails, a [*BatchError] holding the
+// error of each failed request is returned along with the matrix. Errors are
+// keyed by "<storeID>/<productID>".
+func (c *AuthenticatedClient) GetStockMatrix(ctx context.Context, productIDs []string, storeIDs []string) (*StockMatrix, error) {
+	matrix := &StockMatrix{
+		ProductIDs: uniqueValues(productIDs),
+		StoreIDs:   uniqueValues(storeIDs),
+	}
+
+	keys := make([]stockKey, 0, len(matrix.ProductIDs)*len(matrix.StoreIDs))
+	for _, productID := range matrix.ProductIDs {
+		for _, storeID := range matrix.StoreIDs {
+			keys = append(keys, stockKey{productID: productID, storeID: storeID})
+		}
+	}
+
+	statuses, errs := batch(ctx, c.Concurrency, keys, func(ctx context.Context, key stockKey) (*StockStatus, error) {
+		return c.GetStockStatus(ctx, key.storeID, key.productID)
+	})
+
+	matrix.Statuses = make([][]*StockStatus, len(matrix.ProductIDs))
+	for i, productID := range matrix.ProductIDs {
+		matrix.Statuses[i] = make([]*StockStatus, len(matrix.StoreIDs))
+		for j, storeID := range matrix.StoreIDs {
+			matrix.Statuses[i][j] = statuses[stockKey{productID: productID, storeID: storeID}]
+		}
+	}
+
+	keyedErrs := make(map[string]error, len(errs))
+	for key, err := range errs {
+		keyedErrs[key.storeID+"/"+key.productID] = err
+	}
+
+	return matrix, newBatchError(keyedErrs)
+}
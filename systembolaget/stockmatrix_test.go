@@ -0,0 +1,40 @@
+package systembolaget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticatedClientGetStockMatrix(t *testing.T) {
+	server := newStockServer(t)
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	matrix, err := client.GetStockMatrix(context.TODO(), []string{"1", "2", "1"}, []string{"0101", "0404", "0102"})
+
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 2)
+	assert.ErrorIs(t, batchErr.Errors["0404/1"], ErrNotFound)
+	assert.ErrorIs(t, batchErr.Errors["0404/2"], ErrNotFound)
+
+	assert.Equal(t, []string{"1", "2"}, matrix.ProductIDs)
+	assert.Equal(t, []string{"0101", "0404", "0102"}, matrix.StoreIDs)
+	require.Len(t, matrix.Statuses, 2)
+
+	for i, productID := range matrix.ProductIDs {
+		require.Len(t, matrix.Statuses[i], 3)
+		assert.Nil(t, matrix.Statuses[i][1])
+
+		status, ok := matrix.Status(productID, "0102")
+		require.True(t, ok)
+		assert.Equal(t, productID, status.ProductID)
+		assert.Equal(t, 12, status.Stock)
+		assert.Equal(t, "Öl 0102", status.Shelf)
+	}
+
+	_, ok := matrix.Status("1", "0404")
+	assert.False(t, ok)
+}
@@ -0,0 +1,217 @@
+package systembolaget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStoreDirectoryTTL is the default duration for which a
+// [StoreDirectory] keeps its stores before refreshing them.
+const DefaultStoreDirectoryTTL = time.Hour
+
+// storeDirectoryRetryDelay is the maximum delay before retrying a failed
+// refresh of a directory that has stores to fall back on.
+const storeDirectoryRetryDelay = time.Minute
+
+// StoreNotFoundError is returned when a store lookup yields no match. It
+// matches [ErrNotFound] using [errors.Is].
+type StoreNotFoundError struct {
+	SiteID string
+}
+
+// Error implements error.
+func (e *StoreNotFoundError) Error() string {
+	return fmt.Sprintf("store not found: %s", e.SiteID)
+}
+
+// Is implements errors.Is.
+func (e *StoreNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// StoreDirectoryDiff describes how the stores of a [StoreDirectory] changed
+// between two refreshes.
+type StoreDirectoryDiff struct {
+	// Appeared holds stores that were added, sorted by site id.
+	Appeared []Store
+	// Disappeared holds stores that were removed, sorted by site id.
+	Disappeared []Store
+}
+
+// IsEmpty returns whether or not no stores appeared or disappeared.
+func (d StoreDirectoryDiff) IsEmpty() bool {
+	return len(d.Appeared) == 0 && len(d.Disappeared) == 0
+}
+
+// StoreDirectory keeps all stores in memory, answering lookups by site id
+// without making requests. The stores are loaded on first use and refreshed
+// once they are older than the TTL.
+//
+// If a refresh fails, the previous stores are used until a later refresh
+// succeeds.
+//
+// A StoreDirectory is safe for concurrent use.
+type StoreDirectory struct {
+	Client *AuthenticatedClient
+	// TTL is the duration for which stores are kept before being refreshed.
+	// Defaults to [DefaultStoreDirectoryTTL].
+	TTL time.Duration
+	// OnChange is optionally called when stores appear or disappear during a
+	// refresh. It's not called for the initial load.
+	OnChange func(StoreDirectoryDiff)
+
+	mutex     sync.Mutex
+	stores    map[string]Store
+	expiresAt time.Time
+}
+
+// NewStoreDirectory returns a [StoreDirectory] using client to fetch stores.
+func NewStoreDirectory(client *AuthenticatedClient, ttl time.Duration) *StoreDirectory {
+	return &StoreDirectory{
+		Client: client,
+		TTL:    ttl,
+	}
+}
+
+func (d *StoreDirectory) ttl() time.Duration {
+	if d.TTL <= 0 {
+		return DefaultStoreDirectoryTTL
+	}
+	return d.TTL
+}
+
+// Refresh fetches all stores and returns the stores that appeared or
+// disappeared since the previous refresh. The diff of the initial load is
+// empty.
+func (d *StoreDirectory) Refresh(ctx context.Context) (StoreDirectoryDiff, error) {
+	d.mutex.Lock()
+	diff, err := d.refresh(ctx)
+	d.mutex.Unlock()
+
+	if err == nil {
+		d.notify(diff)
+	}
+
+	return diff, err
+}
+
+// refresh fetches all stores. The mutex must be held.
+func (d *StoreDirectory) refresh(ctx context.Context) (StoreDirectoryDiff, error) {
+	list, err := d.Client.GetStores(ctx)
+	if err != nil {
+		return StoreDirectoryDiff{}, err
+	}
+
+	stores := make(map[string]Store, len(list))
+	for _, store := range list {
+		stores[store.SiteID] = store
+	}
+
+	var diff StoreDirectoryDiff
+	if d.stores != nil {
+		for siteID, store := range stores {
+			if _, ok := d.stores[siteID]; !ok {
+				diff.Appeared = append(diff.Appeared, store)
+			}
+		}
+
+		for siteID, store := range d.stores {
+			if _, ok := stores[siteID]; !ok {
+				diff.Disappeared = append(diff.Disappeared, store)
+			}
+		}
+
+		compareSiteID := func(a Store, b Store) int {
+			return strings.Compare(a.SiteID, b.SiteID)
+		}
+		slices.SortFunc(diff.Appeared, compareSiteID)
+		slices.SortFunc(diff.Disappeared, compareSiteID)
+	}
+
+	d.stores = stores
+	d.expiresAt = time.Now().Add(d.ttl())
+	return diff, nil
+}
+
+// load returns the stores, refreshing them if they have expired.
+func (d *StoreDirectory) load(ctx context.Context) (map[string]Store, error) {
+	d.mutex.Lock()
+	if d.stores != nil && time.Now().Before(d.expiresAt) {
+		defer d.mutex.Unlock()
+		return d.stores, nil
+	}
+
+	diff, err := d.refresh(ctx)
+	if err != nil && d.stores != nil {
+		slog.Warn("Failed to refresh stores, using previous stores", slog.Any("error", err))
+		d.expiresAt = time.Now().Add(min(d.ttl(), storeDirectoryRetryDelay))
+		err = nil
+	}
+	stores := d.stores
+	d.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.notify(diff)
+	return stores, nil
+}
+
+// notify calls OnChange if the diff is not empty. The mutex must not be held.
+func (d *StoreDirectory) notify(diff StoreDirectoryDiff) {
+	if !diff.IsEmpty() && d.OnChange != nil {
+		d.OnChange(diff)
+	}
+}
+
+// Get returns the store with the given site id, such as "0102". Returns a
+// [*StoreNotFoundError] if there is no such store.
+func (d *StoreDirectory) Get(ctx context.Context, siteID string) (Store, error) {
+	stores, err := d.load(ctx)
+	if err != nil {
+		return Store{}, err
+	}
+
+	store, ok := stores[siteID]
+	if !ok {
+		return Store{}, &StoreNotFoundError{SiteID: siteID}
+	}
+
+	return store, nil
+}
+
+// Stores returns all stores, sorted by site id.
+func (d *StoreDirectory) Stores(ctx context.Context) ([]Store, error) {
+	stores, err := d.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Store, 0, len(stores))
+	for _, store := range stores {
+		list = append(list, store)
+	}
+
+	slices.SortFunc(list, func(a Store, b Store) int {
+		return strings.Compare(a.SiteID, b.SiteID)
+	})
+
+	return list, nil
+}
+
+// GetStore returns the store with the given site id, such as "0102". Stores
+// are looked up using a [StoreDirectory] owned by the client, with the default
+// TTL. Returns a [*StoreNotFoundError] if there is no such store.
+func (c *AuthenticatedClient) GetStore(ctx context.Context, siteID string) (Store, error) {
+	c.storeDirectoryOnce.Do(func() {
+		c.storeDirectory = NewStoreDirectory(c, DefaultStoreDirectoryTTL)
+	})
+
+	return c.storeDirectory.Get(ctx, siteID)
+}
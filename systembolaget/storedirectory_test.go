@@ -0,0 +1,99 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreDirectory(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	responses := [][]Store{
+		{{SiteID: "0102", DisplayName: "Fältöversten"}, {SiteID: "1406", DisplayName: "Majorna"}},
+		{{SiteID: "0102", DisplayName: "Fältöversten"}, {SiteID: "0110"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		i := min(int(requests.Add(1)), len(responses)) - 1
+		json.NewEncoder(w).Encode(map[string]any{"siteSearchResults": responses[i]})
+	}))
+	defer server.Close()
+
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	var diffs []StoreDirectoryDiff
+	directory := NewStoreDirectory(client, time.Hour)
+	directory.OnChange = func(diff StoreDirectoryDiff) {
+		diffs = append(diffs, diff)
+	}
+
+	store, err := directory.Get(context.TODO(), "0102")
+	require.NoError(t, err)
+	assert.Equal(t, "Fältöversten", store.DisplayName)
+
+	_, err = directory.Get(context.TODO(), "1406")
+	require.NoError(t, err)
+
+	_, err = directory.Get(context.TODO(), "9999")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Lookups are answered from memory
+	assert.EqualValues(t, 1, requests.Load())
+	assert.Empty(t, diffs)
+
+	diff, err := directory.Refresh(context.TODO())
+	require.NoError(t, err)
+	if assert.Len(t, diff.Appeared, 1) {
+		assert.Equal(t, "0110", diff.Appeared[0].SiteID)
+	}
+	if assert.Len(t, diff.Disappeared, 1) {
+		assert.Equal(t, "1406", diff.Disappeared[0].SiteID)
+	}
+	assert.Equal(t, []StoreDirectoryDiff{diff}, diffs)
+
+	_, err = directory.Get(context.TODO(), "1406")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	stores, err := directory.Stores(context.TODO())
+	require.NoError(t, err)
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "0102", stores[0].SiteID)
+
+	// Previous stores are used when a refresh fails
+	fail.Store(true)
+	directory.mutex.Lock()
+	directory.expiresAt = time.Now()
+	directory.mutex.Unlock()
+
+	_, err = directory.Get(context.TODO(), "0110")
+	assert.NoError(t, err)
+
+	_, err = directory.Refresh(context.TODO())
+	assert.Error(t, err)
+}
+
+func TestStoreDirectoryInitialLoadFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	_, err := client.GetStore(context.TODO(), "0102")
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+}
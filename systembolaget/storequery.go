@@ -0,0 +1,218 @@
+package systembolaget
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// StoreQuery filters and ranks stores on the client side, see
+// [StoreQuery.Filter]. The zero value matches all stores.
+type StoreQuery struct {
+	// Text matches stores by DisplayName, Alias, StreetAddress and City,
+	// ignoring case and accents and allowing for minor typos. Each word of the
+	// text must match. Matches are ranked by relevance.
+	Text string
+	// County matches stores in a county, such as "Stockholms län", ignoring
+	// case and accents.
+	County string
+	// City matches stores in a city, such as "Stockholm", ignoring case and
+	// accents.
+	City string
+	// IsTastingStore optionally matches stores that are, or are not, tasting
+	// stores.
+	IsTastingStore *bool
+	// IsAgent optionally matches agents (ombud), or regular stores.
+	IsAgent *bool
+	// IsSvanenCertified optionally matches stores that are, or are not, Svanen
+	// certified.
+	IsSvanenCertified *bool
+	// ExcludeBlocked excludes blocked stores.
+	ExcludeBlocked bool
+	// Predicates are additional filters that all must match.
+	Predicates []func(Store) bool
+}
+
+// ScoredStore is a store and how well it matched a [StoreQuery].
+type ScoredStore struct {
+	Store
+	// Score is the relevance of the store, between 0 and 1. Always 1 for
+	// queries without text.
+	Score float64 `json:"score"`
+}
+
+// storeFieldWeights holds the weight of each field matched by
+// [StoreQuery.Text].
+var storeFieldWeights = []struct {
+	field  func(Store) string
+	weight float64
+}{
+	{field: func(s Store) string { return s.DisplayName }, weight: 1},
+	{field: func(s Store) string { return s.Alias }, weight: 1},
+	{field: func(s Store) string { return s.StreetAddress }, weight: 0.8},
+	{field: func(s Store) string { return s.City }, weight: 0.6},
+}
+
+// Match returns whether or not the store matches the query and, if so, its
+// score.
+func (q *StoreQuery) Match(store Store) (float64, bool) {
+	if q.County != "" && foldAccents(store.County) != foldAccents(q.County) {
+		return 0, false
+	}
+
+	if q.City != "" && foldAccents(store.City) != foldAccents(q.City) {
+		return 0, false
+	}
+
+	if q.IsTastingStore != nil && store.IsTastingStore != *q.IsTastingStore {
+		return 0, false
+	}
+
+	if q.IsAgent != nil && store.IsAgent != *q.IsAgent {
+		return 0, false
+	}
+
+	if q.IsSvanenCertified != nil && store.IsSvanenCertified != *q.IsSvanenCertified {
+		return 0, false
+	}
+
+	if q.ExcludeBlocked && store.IsBlocked {
+		return 0, false
+	}
+
+	for _, predicate := range q.Predicates {
+		if !predicate(store) {
+			return 0, false
+		}
+	}
+
+	tokens := tokenize(q.Text)
+	if len(tokens) == 0 {
+		return 1, true
+	}
+
+	fields := make([][]string, len(storeFieldWeights))
+	for i, field := range storeFieldWeights {
+		fields[i] = tokenize(field.field(store))
+	}
+
+	total := 0.0
+	for _, token := range tokens {
+		best := 0.0
+		for i, field := range storeFieldWeights {
+			best = max(best, field.weight*matchToken(token, fields[i]))
+		}
+
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+
+	return total / float64(len(tokens)), true
+}
+
+// Filter returns the stores matching the query. Stores are ranked by score,
+// best match first. Stores with the same score keep their order.
+func (q *StoreQuery) Filter(stores []Store) []ScoredStore {
+	matches := make([]ScoredStore, 0)
+	for _, store := range stores {
+		if score, ok := q.Match(store); ok {
+			matches = append(matches, ScoredStore{Store: store, Score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a ScoredStore, b ScoredStore) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return matches
+}
+
+// QueryStores fetches all stores and returns those matching the query, see
+// [StoreQuery.Filter].
+func (c *AuthenticatedClient) QueryStores(ctx context.Context, query *StoreQuery) ([]ScoredStore, error) {
+	stores, err := c.GetStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.Filter(stores), nil
+}
+
+// tokenize returns the lower case words of s, with accents folded.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(foldAccents(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchToken returns how well a token matches the words of a field, between 0
+// and 1.
+func matchToken(token string, words []string) float64 {
+	best := 0.0
+	for _, word := range words {
+		switch {
+		case word == token:
+			return 1
+		case strings.HasPrefix(word, token):
+			best = max(best, 0.8)
+		case strings.Contains(word, token):
+			best = max(best, 0.6)
+		case levenshtein(word, token) <= maxTypos(token):
+			best = max(best, 0.5)
+		}
+	}
+
+	// Allow tokens to span several words, such as "karlaplan13"
+	if best < 0.6 && len(words) > 1 && strings.Contains(strings.Join(words, ""), token) {
+		best = 0.6
+	}
+
+	return best
+}
+
+// maxTypos returns the number of typos allowed for a token.
+func maxTypos(token string) int {
+	switch n := len([]rune(token)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
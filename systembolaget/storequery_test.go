@@ -0,0 +1,78 @@
+package systembolaget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var storeQueryStores = []Store{
+	{SiteID: "0102", DisplayName: "Fältöversten", Alias: "Fältöversten", StreetAddress: "Karlaplan 13", City: "STOCKHOLM", County: "Stockholms län", IsSvanenCertified: true},
+	{SiteID: "0110", DisplayName: "Stockholm, Fältöverstens gata", StreetAddress: "Valhallavägen 1", City: "Stockholm", County: "Stockholms län"},
+	{SiteID: "1406", DisplayName: "Majorna", Alias: "Majorna", StreetAddress: "Karl Johansgatan 23", City: "GÖTEBORG", County: "Västra Götalands län", IsTastingStore: true},
+	{SiteID: "9001", DisplayName: "Ombud Öckerö", StreetAddress: "Hamnvägen 2", City: "Öckerö", County: "Västra Götalands län", IsAgent: true},
+	{SiteID: "9002", DisplayName: "Blockerad", City: "Göteborg", County: "Västra Götalands län", IsBlocked: true},
+}
+
+func storeIDs(stores []ScoredStore) []string {
+	ids := make([]string, 0, len(stores))
+	for _, store := range stores {
+		ids = append(ids, store.SiteID)
+	}
+	return ids
+}
+
+func TestStoreQueryText(t *testing.T) {
+	testCases := []struct {
+		Text     string
+		Expected []string
+	}{
+		{Text: "faltoversten", Expected: []string{"0102", "0110"}},
+		{Text: "FÄLTÖVERSTEN", Expected: []string{"0102", "0110"}},
+		{Text: "faltöversetn", Expected: []string{"0102"}},
+		{Text: "karlaplan 13", Expected: []string{"0102"}},
+		{Text: "majorna göteborg", Expected: []string{"1406"}},
+		{Text: "ockero", Expected: []string{"9001"}},
+		{Text: "stockholm", Expected: []string{"0110", "0102"}},
+		{Text: "xyz", Expected: []string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Text, func(t *testing.T) {
+			query := &StoreQuery{Text: testCase.Text}
+			assert.Equal(t, testCase.Expected, storeIDs(query.Filter(storeQueryStores)))
+		})
+	}
+}
+
+func TestStoreQueryPredicates(t *testing.T) {
+	yes, no := true, false
+
+	query := &StoreQuery{County: "vastra gotalands lan"}
+	assert.Equal(t, []string{"1406", "9001", "9002"}, storeIDs(query.Filter(storeQueryStores)))
+
+	query = &StoreQuery{City: "Göteborg", ExcludeBlocked: true}
+	assert.Equal(t, []string{"1406"}, storeIDs(query.Filter(storeQueryStores)))
+
+	query = &StoreQuery{IsAgent: &yes}
+	assert.Equal(t, []string{"9001"}, storeIDs(query.Filter(storeQueryStores)))
+
+	query = &StoreQuery{IsAgent: &no, IsTastingStore: &no, ExcludeBlocked: true}
+	assert.Equal(t, []string{"0102", "0110"}, storeIDs(query.Filter(storeQueryStores)))
+
+	query = &StoreQuery{IsSvanenCertified: &yes}
+	assert.Equal(t, []string{"0102"}, storeIDs(query.Filter(storeQueryStores)))
+
+	query = &StoreQuery{Predicates: []func(Store) bool{func(s Store) bool { return s.SiteID == "1406" }}}
+	assert.Equal(t, []string{"1406"}, storeIDs(query.Filter(storeQueryStores)))
+
+	// The zero value matches all stores
+	assert.Len(t, (&StoreQuery{}).Filter(storeQueryStores), len(storeQueryStores))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("majorna", "majorna"))
+	assert.Equal(t, 1, levenshtein("majorna", "majorn"))
+	assert.Equal(t, 2, levenshtein("faltoversten", "faltoversetn"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
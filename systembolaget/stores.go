@@ -3,7 +3,6 @@ package systembolaget
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -55,20 +54,17 @@ func (c *AuthenticatedClient) SearchStores(ctx context.Context, query string, in
 		queryParams.Set("q", query)
 	}
 
-	u := &url.URL{
-		Scheme:   "https",
-		Host:     "api-extern.systembolaget.se",
-		Path:     "/sb-api-ecommerce/v1/sitesearch/site",
-		RawQuery: queryParams.Encode(),
+	u, err := c.apiURL("/sb-api-ecommerce/v1/sitesearch/site", queryParams)
+	if err != nil {
+		return nil, err
 	}
 
 	header := make(http.Header)
-	header.Set("Origin", "https://www.systembolaget.se")
+	header.Set("Origin", webOrigin(c.WebBaseURL))
 	header.Set("Access-Control-Allow-Origin", "*")
 	header.Set("Pragma", "no-cache")
 	header.Set("Accept", "application/json")
 	header.Set("Cache-Control", "no-cache")
-	header.Set("Ocp-Apim-Subscription-Key", c.APIKey)
 
 	if c.UserAgent != "" {
 		header.Set("User-Agent", c.UserAgent)
@@ -80,16 +76,12 @@ func (c *AuthenticatedClient) SearchStores(ctx context.Context, query string, in
 		Header: header,
 	}).Clone(ctx)
 
-	res, err := c.Client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d - %s", res.StatusCode, res.Status)
-	}
-
 	var result struct {
 		Stores []Store `json:"siteSearchResults"`
 	}
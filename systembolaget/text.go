@@ -0,0 +1,27 @@
+package systembolaget
+
+import "strings"
+
+// accentFolds maps lower case letters with diacritics to their base letters.
+var accentFolds = map[rune]string{
+	'å': "a", 'ä': "a", 'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'æ': "ae",
+	'ö': "o", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ø': "o",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e",
+	'ü': "u", 'ù': "u", 'ú': "u", 'û': "u",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i",
+	'ç': "c", 'ñ': "n", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// foldAccents returns s in lower case with diacritics removed, such that
+// "Fältöversten" becomes "faltoversten".
+func foldAccents(s string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if fold, ok := accentFolds[r]; ok {
+			builder.WriteString(fold)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
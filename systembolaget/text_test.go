@@ -0,0 +1,13 @@
+package systembolaget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldAccents(t *testing.T) {
+	assert.Equal(t, "faltoversten", foldAccents("Fältöversten"))
+	assert.Equal(t, "ostersund", foldAccents("ÖSTERSUND"))
+	assert.Equal(t, "cafe", foldAccents("Café"))
+}
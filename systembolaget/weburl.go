@@ -0,0 +1,362 @@
+package systembolaget
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// slugify returns the friendly URL segment of a value, such as "rott-vin" for
+// "Rött vin".
+func slugify(value string) string {
+	value = strings.ReplaceAll(value, "&", " och ")
+
+	var builder strings.Builder
+	dash := false
+	for _, r := range foldAccents(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return builder.String()
+}
+
+// deslugify guesses the value of a friendly URL segment, such as "Ljus lager"
+// for "ljus-lager". Diacritics can't be restored.
+func deslugify(slug string) string {
+	value := strings.ReplaceAll(slug, "-och-", " & ")
+	value = strings.ReplaceAll(value, "-", " ")
+	r := []rune(value)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+// categorySlugs maps the friendly URL segment of top-level categories to
+// their values.
+var categorySlugs = map[string]string{
+	"ol":                     "Öl",
+	"vin":                    "Vin",
+	"sprit":                  "Sprit",
+	"cider-och-blanddrycker": "Cider & blanddrycker",
+	"alkoholfritt":           "Alkoholfritt",
+	"presentartiklar":        "Presentartiklar",
+}
+
+// assortmentSlugs maps the friendly URL segment of assortments to their
+// values.
+var assortmentSlugs = map[string]string{
+	"fast-sortiment":        "Fast sortiment",
+	"tillfalligt-sortiment": "Tillfälligt sortiment",
+	"bestallningssortiment": "Beställningssortiment",
+	"lokalt-och-smaskaligt": "Lokalt & Småskaligt",
+	"presentsortiment":      "Presentsortiment",
+	"sasong":                "Säsong",
+}
+
+// countrySlugs maps the friendly URL segment of common countries of origin to
+// their values.
+var countrySlugs = map[string]string{
+	"argentina":      "Argentina",
+	"australien":     "Australien",
+	"belgien":        "Belgien",
+	"chile":          "Chile",
+	"danmark":        "Danmark",
+	"england":        "England",
+	"finland":        "Finland",
+	"frankrike":      "Frankrike",
+	"grekland":       "Grekland",
+	"irland":         "Irland",
+	"italien":        "Italien",
+	"japan":          "Japan",
+	"kanada":         "Kanada",
+	"mexiko":         "Mexiko",
+	"nederlanderna":  "Nederländerna",
+	"norge":          "Norge",
+	"nya-zeeland":    "Nya Zeeland",
+	"polen":          "Polen",
+	"portugal":       "Portugal",
+	"schweiz":        "Schweiz",
+	"skottland":      "Skottland",
+	"spanien":        "Spanien",
+	"storbritannien": "Storbritannien",
+	"sverige":        "Sverige",
+	"sydafrika":      "Sydafrika",
+	"tjeckien":       "Tjeckien",
+	"tyskland":       "Tyskland",
+	"ungern":         "Ungern",
+	"usa":            "USA",
+	"osterrike":      "Österrike",
+}
+
+// webHosts are the hosts of the website.
+var webHosts = []string{"www.systembolaget.se", "systembolaget.se"}
+
+const (
+	// webSearchPath is the path of free text searches on the website.
+	webSearchPath = "/sok/"
+	// webAssortmentPath is the path of the assortment listing on the website.
+	webAssortmentPath = "/sortiment/"
+	// webProductPath is the path of products on the website.
+	webProductPath = "/produkt/"
+)
+
+// ParseWebURL parses a search or listing URL of the website, such as
+// "https://www.systembolaget.se/sortiment/ol/ljus-lager/?country=Sverige".
+//
+// Friendly path segments are interpreted as category levels, followed by an
+// optional country of origin or assortment, such as in
+// "/sortiment/ol/sverige/". The query string is interpreted as query
+// parameters, see [FiltersFromValues] and [SearchOptionsFromValues]. The
+// values of subcategories are guessed from their segments, which may be wrong
+// for values with diacritics. Use [AuthenticatedClient.ResolveWebURL] to
+// resolve them using the API.
+//
+// URLs of hosts other than systembolaget.se are rejected.
+func ParseWebURL(rawURL string) (*Query, error) {
+	query, _, err := parseWebURL(rawURL, "")
+	return query, err
+}
+
+// parseWebURL parses a URL of the website, also returning the friendly path
+// segments of the category levels. URLs relative to webBaseURL, if set, are
+// accepted in addition to those of systembolaget.se.
+func parseWebURL(rawURL string, webBaseURL string) (*Query, []string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path := u.Path
+	if base, err := url.Parse(webBaseURL); err == nil && base.Host != "" && strings.EqualFold(u.Host, base.Host) {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	} else if !slices.Contains(webHosts, strings.ToLower(u.Hostname())) {
+		return nil, nil, fmt.Errorf("unsupported host: %q", u.Host)
+	}
+
+	values := u.Query()
+	options, err := SearchOptionsFromValues(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := &Query{
+		Options: options,
+		Filters: make([]SearchFilter, 0),
+	}
+
+	path = strings.Trim(path, "/")
+	segments := make([]string, 0)
+	if after, ok := strings.CutPrefix(path+"/", strings.TrimPrefix(webAssortmentPath, "/")); ok {
+		for segment := range strings.SplitSeq(after, "/") {
+			if segment != "" {
+				segments = append(segments, segment)
+			}
+		}
+	} else if path+"/" != strings.TrimPrefix(webSearchPath, "/") && path != "" {
+		return nil, nil, fmt.Errorf("unsupported path: %s", u.Path)
+	}
+
+	// Countries and assortments follow the category levels, if any
+	levels := make([]string, 0, len(segments))
+	filters := make([]SearchFilter, 0)
+	for i, segment := range segments {
+		if country, ok := countrySlugs[segment]; ok {
+			filters = append(filters, FilterByOrigin(country))
+			continue
+		}
+
+		if assortment, ok := assortmentSlugs[segment]; ok {
+			filters = append(filters, FilterByAssortment(assortment))
+			continue
+		}
+
+		if len(filters) > 0 {
+			return nil, nil, fmt.Errorf("unsupported path segment: %s", segment)
+		}
+
+		if i == 0 {
+			value, ok := categorySlugs[segment]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown category: %s", segment)
+			}
+			levels = append(levels, value)
+		} else {
+			levels = append(levels, deslugify(segment))
+		}
+	}
+
+	if len(levels) > maxFilterArgs {
+		return nil, nil, fmt.Errorf("too many category levels: %d", len(levels))
+	}
+
+	if len(levels) > 0 {
+		query.Filters = append(query.Filters, newFilter(FilterKindCategory, levels...))
+	}
+
+	query.Filters = append(query.Filters, filters...)
+	query.Filters = append(query.Filters, FiltersFromValues(values)...)
+	return query, segments[:len(levels)], nil
+}
+
+// ResolveWebURL parses a URL like [ParseWebURL], but resolves the values of
+// the friendly path segments by matching them against the category facets
+// returned by the API. URLs of the client's WebBaseURL are also accepted.
+func (c *AuthenticatedClient) ResolveWebURL(ctx context.Context, rawURL string) (*Query, error) {
+	query, segments, err := parseWebURL(rawURL, c.WebBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return query, nil
+	}
+
+	levels := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		filters := make([]SearchFilter, 0, 1)
+		if len(levels) > 0 {
+			filters = append(filters, newFilter(FilterKindCategory, levels...))
+		}
+
+		result, err := c.Search(ctx, &SearchOptions{PageSize: 1}, filters...)
+		if err != nil {
+			return nil, err
+		}
+
+		facet, ok := result.Facets().Lookup("categoryLevel" + strconv.Itoa(i+1))
+		if !ok {
+			return nil, fmt.Errorf("unable to resolve category level %d", i+1)
+		}
+
+		value, ok := matchSlug(facet, segment)
+		if !ok {
+			return nil, fmt.Errorf("unknown category: %s", segment)
+		}
+
+		levels = append(levels, value)
+	}
+
+	// The category filter is always the first filter, see parseWebURL
+	query.Filters[0] = newFilter(FilterKindCategory, levels...)
+	return query, nil
+}
+
+// matchSlug returns the value of the facet matching a friendly URL segment.
+func matchSlug(facet *Facet, slug string) (string, bool) {
+	for _, value := range facet.Values {
+		friendlyURL := strings.Trim(value.FriendlyURL, "/")
+		if friendlyURL == slug || strings.HasSuffix(friendlyURL, "/"+slug) || slugify(value.Value) == slug {
+			return value.Value, true
+		}
+	}
+	return "", false
+}
+
+// SearchOptionsFromValues returns the search options of query parameters,
+// such as "page" and "sortBy".
+func SearchOptionsFromValues(values url.Values) (SearchOptions, error) {
+	var options SearchOptions
+
+	for _, parameter := range []struct {
+		name  string
+		value *int
+	}{{"page", &options.Page}, {"size", &options.PageSize}} {
+		if v := values.Get(parameter.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return SearchOptions{}, fmt.Errorf("invalid %s: %s", parameter.name, v)
+			}
+			*parameter.value = n
+		}
+	}
+
+	options.SortBy = SortProperty(values.Get("sortBy"))
+	options.SortDirection = SortDirection(values.Get("sortDirection"))
+	return options, nil
+}
+
+// WebURL returns a shareable URL of the search on the website at webBaseURL.
+// Defaults to [DefaultWebBaseURL].
+func (q *Query) WebURL(webBaseURL string) string {
+	path := webSearchPath
+	filters := q.Filters
+
+	// The first category is expressed using friendly path segments
+	for i, filter := range q.Filters {
+		if filter.Kind() != FilterKindCategory {
+			continue
+		}
+
+		segments := make([]string, 0)
+		for _, level := range filter.Args() {
+			segments = append(segments, slugify(level))
+		}
+		path = webAssortmentPath + strings.Join(segments, "/") + "/"
+
+		filters = make([]SearchFilter, 0, len(q.Filters)-1)
+		filters = append(filters, q.Filters[:i]...)
+		filters = append(filters, q.Filters[i+1:]...)
+		break
+	}
+
+	values := FiltersToValues(filters...)
+	if q.Options.SortBy != "" {
+		values.Set("sortBy", string(q.Options.SortBy))
+	}
+	if q.Options.SortDirection != "" {
+		values.Set("sortDirection", string(q.Options.SortDirection))
+	}
+	if q.Options.Page > 1 {
+		values.Set("page", formatInt(q.Options.Page))
+	}
+
+	var query url.Values
+	if len(values) > 0 {
+		query = values
+	}
+
+	u, err := resolveURL(webBaseURL, DefaultWebBaseURL, path, query)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// WebURL returns the URL of the product's page on the website at webBaseURL,
+// such as "https://www.systembolaget.se/produkt/ol/melleruds-125303/".
+// Defaults to [DefaultWebBaseURL].
+func (p Product) WebURL(webBaseURL string) (string, bool) {
+	category, ok := p.getNonEmptyString("categoryLevel1")
+	if !ok {
+		return "", false
+	}
+
+	name, ok := p.Title()
+	if !ok {
+		return "", false
+	}
+
+	number, ok := p.Number()
+	if !ok {
+		return "", false
+	}
+
+	path := webProductPath + slugify(category) + "/" + slugify(name) + "-" + number + "/"
+	u, err := resolveURL(webBaseURL, DefaultWebBaseURL, path, nil)
+	if err != nil {
+		return "", false
+	}
+	return u.String(), true
+}
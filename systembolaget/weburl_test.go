@@ -0,0 +1,131 @@
+package systembolaget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "ol", slugify("Öl"))
+	assert.Equal(t, "rott-vin", slugify("Rött vin"))
+	assert.Equal(t, "cider-och-blanddrycker", slugify("Cider & blanddrycker"))
+	assert.Equal(t, "pilsner-tysk-stil", slugify("Pilsner - tysk stil"))
+}
+
+func TestParseWebURL(t *testing.T) {
+	query, err := ParseWebURL("https://www.systembolaget.se/sortiment/ol/ljus-lager/?country=Sverige&sortBy=Price&sortDirection=Ascending&page=2")
+	require.NoError(t, err)
+
+	assert.Equal(t, SearchOptions{Page: 2, SortBy: SortPropertyPrice, SortDirection: SortDirectionAscending}, query.Options)
+	assert.Equal(t, []SearchFilter{
+		FilterByCategory("Öl", "Ljus lager", ""),
+		FilterByOrigin("Sverige"),
+	}, query.Filters)
+
+	assert.Equal(t, "https://www.systembolaget.se/sortiment/ol/ljus-lager/?country=Sverige&page=2&sortBy=Price&sortDirection=Ascending", query.WebURL(""))
+
+	query, err = ParseWebURL("https://www.systembolaget.se/sok/?textQuery=guinness")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByQuery("guinness")}, query.Filters)
+	assert.Equal(t, "https://www.systembolaget.se/sok/?textQuery=guinness", query.WebURL(""))
+
+	// Parameters that can't be expressed using filters are kept as is
+	query, err = ParseWebURL("https://www.systembolaget.se/sok/?price.min=10&price.min=20&storeId=0102&textQuery=a&textQuery=b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.systembolaget.se/sok/?price.min=10&price.min=20&storeId=0102&textQuery=a&textQuery=b", query.WebURL(""))
+
+	_, err = ParseWebURL("https://www.systembolaget.se/produkt/ol/melleruds-125303/")
+	assert.Error(t, err)
+}
+
+func TestParseWebURLSegments(t *testing.T) {
+	query, err := ParseWebURL("https://systembolaget.se/sortiment/vin/rott-vin/italien/fast-sortiment/")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{
+		FilterByCategory("Vin", "Rott vin", ""),
+		FilterByOrigin("Italien"),
+		FilterByAssortment("Fast sortiment"),
+	}, query.Filters)
+
+	query, err = ParseWebURL("https://www.systembolaget.se/sortiment/tillfalligt-sortiment/")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByAssortment("Tillfälligt sortiment")}, query.Filters)
+
+	testCases := []string{
+		"https://example.com/sortiment/ol/",
+		"https://www.systembolaget.se.example.com/sortiment/ol/",
+		"/sortiment/ol/",
+		"https://www.systembolaget.se/sortiment/okand/",
+		"https://www.systembolaget.se/sortiment/ol/sverige/ljus-lager/",
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			_, err := ParseWebURL(testCase)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuthenticatedClientResolveWebURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result SearchResult
+		result.Filters = []Filter{
+			{
+				Name:            "CategoryLevel1",
+				SearchModifiers: []SearchModifier{{Value: "Vin", FriendlyURL: "vin"}},
+			},
+		}
+		if r.URL.Query().Get("categoryLevel1") == "Vin" {
+			result.Filters[0].SearchModifiers[0].IsActive = true
+			result.Filters[0].Child = &Filter{
+				Name:            "CategoryLevel2",
+				SearchModifiers: []SearchModifier{{Value: "Rött vin", FriendlyURL: "vin/rott-vin"}},
+			}
+		}
+		json.NewEncoder(w).Encode(&result)
+	}))
+	defer server.Close()
+
+	client := &AuthenticatedClient{Client: server.Client(), APIBaseURL: server.URL}
+
+	query, err := client.ResolveWebURL(context.TODO(), "https://www.systembolaget.se/sortiment/vin/rott-vin/")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByCategory("Vin", "Rött vin", "")}, query.Filters)
+
+	// Countries are not resolved as categories
+	query, err = client.ResolveWebURL(context.TODO(), "https://www.systembolaget.se/sortiment/vin/rott-vin/italien/")
+	require.NoError(t, err)
+	assert.Equal(t, []SearchFilter{FilterByCategory("Vin", "Rött vin", ""), FilterByOrigin("Italien")}, query.Filters)
+
+	// URLs of a custom website are accepted
+	client.WebBaseURL = "http://localhost:8080/base"
+	webURL := query.WebURL(client.WebBaseURL)
+	assert.Equal(t, "http://localhost:8080/base/sortiment/vin/rott-vin/?country=Italien", webURL)
+	resolved, err := client.ResolveWebURL(context.TODO(), webURL)
+	require.NoError(t, err)
+	assert.Equal(t, query, resolved)
+
+	_, err = client.ResolveWebURL(context.TODO(), "http://localhost:8081/sok/?textQuery=x")
+	assert.Error(t, err)
+	_, err = ParseWebURL(webURL)
+	assert.Error(t, err)
+}
+
+func TestProductWebURL(t *testing.T) {
+	product := Product{
+		"categoryLevel1":  "Öl",
+		"productNameBold": "Melleruds",
+		"productNumber":   "125303",
+	}
+
+	u, ok := product.WebURL("")
+	require.True(t, ok)
+	assert.Equal(t, "https://www.systembolaget.se/produkt/ol/melleruds-125303/", u)
+}